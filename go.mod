@@ -8,6 +8,7 @@ require (
 	github.com/blang/semver v3.5.1+incompatible
 	github.com/d5/tengo/v2 v2.6.0
 	github.com/google/go-github/v32 v32.1.0
+	github.com/gorilla/websocket v1.4.2
 	github.com/json-iterator/go v1.1.10
 	github.com/karrick/godirwalk v1.15.6
 	github.com/logrusorgru/aurora v2.0.3+incompatible
@@ -20,21 +20,32 @@ type Options struct {
 	JSON              bool // JSON writes json output to files
 	JSONRequests      bool // write requests/responses for matches in JSON output
 	EnableProgressBar bool // Enable progrss bar
-
-	Stdin              bool                   // Stdin specifies whether stdin input was given to the process
-	Templates          multiStringFlag        // Signature specifies the template/templates to use
-	ExcludedTemplates  multiStringFlag        // Signature specifies the template/templates to exclude
-	Severity           string                 // Filter templates based on their severity and only run the matching ones.
-	Target             string                 // Target is a single URL/Domain to scan usng a template
-	Targets            string                 // Targets specifies the targets to scan using templates.
-	Threads            int                    // Thread controls the number of concurrent requests to make.
-	Timeout            int                    // Timeout is the seconds to wait for a response from the server.
-	Retries            int                    // Retries is the number of times to retry the request
-	Output             string                 // Output is the file to write found subdomains to.
-	ProxyURL           string                 // ProxyURL is the URL for the proxy server
-	ProxySocksURL      string                 // ProxySocksURL is the URL for the proxy socks server
-	CustomHeaders      requests.CustomHeaders // Custom global headers
-	TemplatesDirectory string                 // TemplatesDirectory is the directory to use for storing templates
+	HostFirst         bool // HostFirst runs all the templates on a host before moving to the next one
+	KeepAlive         bool // KeepAlive reuses the HTTP connections between requests
+
+	Stdin               bool                   // Stdin specifies whether stdin input was given to the process
+	Templates           multiStringFlag        // Signature specifies the template/templates to use
+	ExcludedTemplates   multiStringFlag        // Signature specifies the template/templates to exclude
+	Severity            string                 // Filter templates based on their severity and only run the matching ones.
+	Target              string                 // Target is a single URL/Domain to scan usng a template
+	Targets             string                 // Targets specifies the targets to scan using templates.
+	Threads             int                    // Thread controls the number of concurrent requests to make.
+	TemplateConcurrency int                    // TemplateConcurrency is the number of templates to run in parallel.
+	HostConcurrency     int                    // HostConcurrency is the number of hosts to scan in parallel.
+	Timeout             int                    // Timeout is the seconds to wait for a response from the server.
+	Retries             int                    // Retries is the number of times to retry the request
+	RateLimit           int                    // RateLimit is the maximum number of requests to send per second
+	MaxHostConns        int                    // MaxHostConns is the maximum number of HTTP connections to a host
+	MaxHostErrors       int                    // MaxHostErrors is the number of connection errors after which a host is skipped
+	RateLimitPerHost    int                    // RateLimitPerHost is the maximum number of requests to send per second to a host
+	Output              string                 // Output is the file to write found subdomains to.
+	Sarif               string                 // Sarif is the file to write the SARIF report to.
+	ReportDir           string                 // ReportDir is the directory to write the Markdown and HTML report to.
+	Resume              string                 // Resume is the file to save the scan state to, for resuming it.
+	ProxyURL            string                 // ProxyURL is the URL for the proxy server
+	ProxySocksURL       string                 // ProxySocksURL is the URL for the proxy socks server
+	CustomHeaders       requests.CustomHeaders // Custom global headers
+	TemplatesDirectory  string                 // TemplatesDirectory is the directory to use for storing templates
 }
 
 type multiStringFlag []string
@@ -58,6 +69,9 @@ func ParseOptions() *Options {
 	flag.StringVar(&options.Severity, "severity", "", "Filter templates based on their severity and only run the matching ones. Comma-separated values can be used to specify multiple severities.")
 	flag.StringVar(&options.Targets, "l", "", "List of URLs to run templates on")
 	flag.StringVar(&options.Output, "o", "", "File to write output to (optional)")
+	flag.StringVar(&options.Sarif, "sarif", "", "File to write the SARIF report to (optional)")
+	flag.StringVar(&options.ReportDir, "report-dir", "", "Directory to write the Markdown and HTML report to (optional)")
+	flag.StringVar(&options.Resume, "resume", "", "File to save the scan state to, resuming the scan if it exists (optional)")
 	flag.StringVar(&options.ProxyURL, "proxy-url", "", "URL of the proxy server")
 	flag.StringVar(&options.ProxySocksURL, "proxy-socks-url", "", "URL of the proxy socks server")
 	flag.BoolVar(&options.Silent, "silent", false, "Show only results in output")
@@ -65,8 +79,16 @@ func ParseOptions() *Options {
 	flag.BoolVar(&options.Verbose, "v", false, "Show Verbose output")
 	flag.BoolVar(&options.NoColor, "nC", false, "Don't Use colors in output")
 	flag.IntVar(&options.Threads, "c", 50, "Number of concurrent requests to make")
+	flag.IntVar(&options.TemplateConcurrency, "template-concurrency", 10, "Number of templates to run in parallel")
+	flag.IntVar(&options.HostConcurrency, "host-concurrency", 25, "Number of hosts to scan in parallel")
+	flag.BoolVar(&options.HostFirst, "host-first", false, "Run all the templates on a host before moving to the next one")
 	flag.IntVar(&options.Timeout, "timeout", 5, "Time to wait in seconds before timeout")
 	flag.IntVar(&options.Retries, "retries", 1, "Number of times to retry a failed request")
+	flag.IntVar(&options.RateLimit, "rate-limit", 0, "Maximum number of requests to send per second (0 for no limit)")
+	flag.IntVar(&options.RateLimitPerHost, "rate-limit-per-host", 0, "Maximum number of requests to send per second to a host (0 for no limit)")
+	flag.BoolVar(&options.KeepAlive, "keep-alive", false, "Reuse the HTTP connections between requests")
+	flag.IntVar(&options.MaxHostConns, "max-host-conns", 0, "Maximum number of HTTP connections to a host (0 for no limit)")
+	flag.IntVar(&options.MaxHostErrors, "max-host-errors", 30, "Number of consecutive connection errors after which a host is skipped (0 to never skip)")
 	flag.Var(&options.CustomHeaders, "H", "Custom Header.")
 	flag.BoolVar(&options.Debug, "debug", false, "Allow debugging of request/responses")
 	flag.BoolVar(&options.UpdateTemplates, "update-templates", false, "Update Templates updates the installed templates (optional)")
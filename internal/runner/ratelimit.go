@@ -0,0 +1,39 @@
+package runner
+
+import (
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+)
+
+// rateStatisticsInterval is the interval at which the effective request rate is shown
+const rateStatisticsInterval = 5 * time.Second
+
+// showRateStatistics periodically shows the effective rate of the requests sent
+// through the rate limiter until the returned function is called.
+func (r *Runner) showRateStatistics(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		last := r.rateLimiter.Requests()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				requests := r.rateLimiter.Requests()
+				current := float64(requests-last) / interval.Seconds()
+				last = requests
+
+				gologger.Infof("Requests: %d, current rate: %.1f req/s, average rate: %.1f req/s\n", requests, current, r.rateLimiter.AverageRate())
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
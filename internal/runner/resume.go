@@ -0,0 +1,228 @@
+package runner
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/projectdiscovery/gologger"
+)
+
+// resumeSaveInterval is the interval at which the resume state is saved during a scan
+const resumeSaveInterval = 10 * time.Second
+
+// resumeState contains the (template, target) pairs completed during a scan, so that
+// an interrupted scan can be resumed without running them again. All the methods are
+// safe to call on a nil state, which records nothing.
+type resumeState struct {
+	path      string
+	completed map[string]map[string]struct{}
+	dirty     bool
+	mutex     sync.Mutex
+}
+
+// resumeFile is the format of the resume state file
+type resumeFile struct {
+	// Completed contains the targets completed for each template ID
+	Completed map[string][]string `json:"completed"`
+}
+
+// loadResumeState loads the resume state from a file. A missing file is an empty state.
+func loadResumeState(path string) (*resumeState, error) {
+	state := &resumeState{path: path, completed: make(map[string]map[string]struct{})}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	file := &resumeFile{}
+	if err := jsoniter.Unmarshal(data, file); err != nil {
+		return nil, err
+	}
+
+	for template, targets := range file.Completed {
+		for _, target := range targets {
+			state.add(template, target)
+		}
+	}
+
+	return state, nil
+}
+
+// add adds a completed pair to the state. The caller must hold the lock, if needed.
+func (s *resumeState) add(template, target string) {
+	targets, ok := s.completed[template]
+	if !ok {
+		targets = make(map[string]struct{})
+		s.completed[template] = targets
+	}
+
+	targets[target] = struct{}{}
+}
+
+// count returns the number of completed pairs
+func (s *resumeState) count() int {
+	if s == nil {
+		return 0
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	count := 0
+	for _, targets := range s.completed {
+		count += len(targets)
+	}
+
+	return count
+}
+
+// isCompleted returns whether a template was completed on a target
+func (s *resumeState) isCompleted(template, target string) bool {
+	if s == nil {
+		return false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, ok := s.completed[template][target]
+
+	return ok
+}
+
+// markCompleted records that a template was completed on a target
+func (s *resumeState) markCompleted(template, target string) {
+	if s == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.add(template, target)
+	s.dirty = true
+}
+
+// save writes the state to its file if it changed since the last save. The file is
+// replaced atomically so an interruption never leaves a truncated state behind.
+func (s *resumeState) save() error {
+	if s == nil {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+
+	file := &resumeFile{Completed: make(map[string][]string, len(s.completed))}
+
+	for template, targets := range s.completed {
+		list := make([]string, 0, len(targets))
+		for target := range targets {
+			list = append(list, target)
+		}
+
+		sort.Strings(list)
+		file.Completed[template] = list
+	}
+
+	data, err := jsoniter.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	temp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(temp.Name())
+
+		return err
+	}
+
+	if err := os.Rename(temp.Name(), s.path); err != nil {
+		os.Remove(temp.Name())
+
+		return err
+	}
+
+	s.dirty = false
+
+	return nil
+}
+
+// autoSave saves the state periodically until the returned function is called.
+// The function returns once any save in progress is done.
+func (s *resumeState) autoSave(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer close(stopped)
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := s.save(); err != nil {
+					gologger.Warningf("Could not save resume state '%s': %s\n", s.path, err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+		<-stopped
+	}
+}
+
+// handleInterrupt cancels the scan when it is interrupted, so that the requests in progress
+// are drained and the outputs written before the resume state is saved. The interrupt is
+// handled until the returned function is called.
+func (r *Runner) handleInterrupt(cancel context.CancelFunc) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-signals:
+			gologger.Infof("Scan interrupted, waiting for the requests in progress to finish\n")
+			cancel()
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
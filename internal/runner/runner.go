@@ -12,7 +12,6 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
-	"sync"
 
 	"github.com/logrusorgru/aurora"
 
@@ -23,6 +22,9 @@ import (
 	"github.com/projectdiscovery/nuclei/v2/internal/progress"
 	"github.com/projectdiscovery/nuclei/v2/pkg/atomicboolean"
 	"github.com/projectdiscovery/nuclei/v2/pkg/executer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/hosterrors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/ratelimit"
+	"github.com/projectdiscovery/nuclei/v2/pkg/reporting"
 	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
 	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
 	"github.com/projectdiscovery/nuclei/v2/pkg/workflows"
@@ -34,8 +36,21 @@ type Runner struct {
 	inputCount int64
 
 	// output is the output file to write if any
-	output      *os.File
-	outputMutex *sync.Mutex
+	output *os.File
+	// writer writes the results to the screen and the output file
+	writer executer.OutputWriter
+	// sarif collects the results for the SARIF report if any
+	sarif *reporting.SarifWriter
+	// report collects the results for the report directory if any
+	report *reporting.ReportWriter
+	// resume contains the completed template/target pairs if resuming
+	resume *resumeState
+	// rateLimiter limits the requests sent per second if asked
+	rateLimiter *ratelimit.Limiter
+	// hostErrors tracks the hosts which stopped responding
+	hostErrors *hosterrors.Cache
+	// clientPool shares the HTTP clients between the templates
+	clientPool *executer.HTTPClientPool
 
 	tempFile        string
 	templatesConfig *nucleiConfig
@@ -60,8 +75,7 @@ type WorkflowTemplates struct {
 // New creates a new client for running enumeration process.
 func New(options *Options) (*Runner, error) {
 	runner := &Runner{
-		outputMutex: &sync.Mutex{},
-		options:     options,
+		options: options,
 	}
 
 	if err := runner.updateTemplates(); err != nil {
@@ -160,9 +174,27 @@ func New(options *Options) (*Runner, error) {
 		gologger.Labelf("Supplied input was automatically deduplicated (%d removed).", dupeCount)
 	}
 
-	// Create the output file if asked
+	// Load the state of the previous run if resuming
+	if options.Resume != "" {
+		runner.resume, err = loadResumeState(options.Resume)
+		if err != nil {
+			gologger.Fatalf("Could not read resume state '%s': %s\n", options.Resume, err)
+		}
+
+		if count := runner.resume.count(); count > 0 {
+			gologger.Infof("Resuming scan, skipping %d completed template/target pairs\n", count)
+		}
+	}
+
+	// Create the output file if asked. When resuming, the results
+	// are appended to the output of the previous run.
 	if options.Output != "" {
-		output, err := os.Create(options.Output)
+		flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if options.Resume != "" {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+
+		output, err := os.OpenFile(options.Output, flags, 0644)
 		if err != nil {
 			gologger.Fatalf("Could not create output file '%s': %s\n", options.Output, err)
 		}
@@ -170,16 +202,47 @@ func New(options *Options) (*Runner, error) {
 		runner.output = output
 	}
 
+	// Create the writer for the results, with the output file if any
+	var outputWriter io.Writer
+	if runner.output != nil {
+		outputWriter = runner.output
+	}
+
+	if options.JSON {
+		runner.writer = executer.NewJSONWriter(outputWriter, options.JSONRequests)
+	} else {
+		runner.writer = executer.NewTextWriter(outputWriter, runner.colorizer, runner.decolorizer)
+	}
+
+	// Collect the results for the SARIF report if asked
+	if options.Sarif != "" {
+		runner.sarif = reporting.NewSarifWriter(Version)
+		runner.writer = executer.NewMultiWriter(runner.writer, runner.sarif)
+	}
+
+	// Collect the results for the report directory if asked
+	if options.ReportDir != "" {
+		runner.report = reporting.NewReportWriter()
+		runner.writer = executer.NewMultiWriter(runner.writer, runner.report)
+	}
+
 	// Creates the progress tracking object
 	runner.progress = progress.NewProgress(runner.options.NoColor, !options.Silent && options.EnableProgressBar)
 
 	runner.limiter = make(chan struct{}, options.Threads)
+	runner.rateLimiter = ratelimit.New(options.RateLimit, options.RateLimitPerHost)
+	runner.hostErrors = hosterrors.New(options.MaxHostErrors)
+	runner.clientPool = executer.NewHTTPClientPool(&executer.HTTPClientPoolOptions{
+		KeepAlive:       options.KeepAlive,
+		MaxConnsPerHost: options.MaxHostConns,
+	})
 
 	return runner, nil
 }
 
 // Close releases all the resources and cleans up
 func (r *Runner) Close() {
+	r.writer.Close()
 	r.output.Close()
 	os.Remove(r.tempFile)
 }
@@ -431,51 +494,78 @@ func (r *Runner) RunEnumeration() {
 	for _, t := range availableTemplates {
 		switch av := t.(type) {
 		case *templates.Template:
-			totalRequests += (av.GetHTTPRequestCount() + av.GetDNSRequestCount()) * r.inputCount
+			totalRequests += (av.GetHTTPRequestCount() + av.GetDNSRequestCount() + av.GetNetworkRequestCount() + av.GetSSLRequestCount() + av.GetFileRequestCount() + av.GetWebSocketRequestCount()) * r.inputCount
 		case *workflows.Workflow:
 			// workflows will dynamically adjust the totals while running, as
 			// it can't be know in advance which requests will be called
 		} // nolint:wsl // comment
 	}
 
-	var (
-		wgtemplates sync.WaitGroup
-		results     atomicboolean.AtomBool
-	)
+	var results atomicboolean.AtomBool
+
+	ctx := context.Background()
 
 	if r.inputCount == 0 {
 		gologger.Errorf("Could not find any valid input URLs.")
 	} else if totalRequests > 0 || hasWorkflows {
-		ctx := context.Background()
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+
+		// periodically save the progress of the scan if resuming
+		stopAutoSave := func() {}
+		stopInterrupt := func() {}
+
+		if r.resume != nil {
+			stopAutoSave = r.resume.autoSave(resumeSaveInterval)
+			stopInterrupt = r.handleInterrupt(cancel)
+		}
+
+		// show the effective request rate if limited
+		stopRateStatistics := func() {}
+		if r.rateLimiter != nil {
+			stopRateStatistics = r.showRateStatistics(rateStatisticsInterval)
+		}
+
 		// tracks global progress and captures stdout/stderr until p.Wait finishes
 		p := r.progress
 		p.InitProgressbar(r.inputCount, templateCount, totalRequests)
 
-		for _, t := range availableTemplates {
-			wgtemplates.Add(1)
-			go func(template interface{}) {
-				defer wgtemplates.Done()
-				switch tt := template.(type) {
-				case *templates.Template:
-					for _, request := range tt.RequestsDNS {
-						results.Or(r.processTemplateWithList(ctx, p, tt, request))
-					}
-					for _, request := range tt.BulkRequestsHTTP {
-						results.Or(r.processTemplateWithList(ctx, p, tt, request))
-					}
-				case *workflows.Workflow:
-					workflow := template.(*workflows.Workflow)
-					r.ProcessWorkflowWithList(p, workflow)
-				}
-			}(t)
+		if r.options.HostFirst {
+			results.Or(r.runHostFirst(ctx, p, availableTemplates))
+		} else {
+			results.Or(r.runTemplateFirst(ctx, p, availableTemplates))
 		}
 
-		wgtemplates.Wait()
 		p.Wait()
+		stopInterrupt()
+		stopAutoSave()
+		stopRateStatistics()
+	}
+
+	if deadHosts := r.hostErrors.DeadHosts(); len(deadHosts) > 0 {
+		gologger.Warningf("Skipped %d unresponsive hosts: %s\n", len(deadHosts), strings.Join(deadHosts, ", "))
+	}
+
+	interrupted := ctx.Err() != nil
+
+	if r.resume != nil {
+		if interrupted {
+			gologger.Infof("Saving resume state to '%s'\n", r.options.Resume)
+
+			if err := r.resume.save(); err != nil {
+				gologger.Errorf("Could not save resume state '%s': %s\n", r.options.Resume, err)
+			}
+		} else {
+			// The scan is complete, the resume state is no longer needed
+			os.Remove(r.options.Resume)
+		}
 	}
 
 	if !results.Get() {
-		if r.output != nil {
+		// Keep the output of the previous run when resuming
+		if r.output != nil && r.options.Resume == "" {
 			outputFile := r.output.Name()
 			r.output.Close()
 			os.Remove(outputFile)
@@ -483,149 +573,179 @@ func (r *Runner) RunEnumeration() {
 
 		gologger.Infof("No results found. Happy hacking!")
 	}
-}
 
-// processTemplateWithList processes a template and runs the enumeration on all the targets
-func (r *Runner) processTemplateWithList(ctx context.Context, p progress.IProgress, template *templates.Template, request interface{}) bool {
-	var writer *bufio.Writer
-	if r.output != nil {
-		writer = bufio.NewWriter(r.output)
-		defer writer.Flush()
+	if r.sarif != nil {
+		if err := r.sarif.WriteFile(r.options.Sarif); err != nil {
+			gologger.Errorf("Could not write SARIF report '%s': %s\n", r.options.Sarif, err)
+		}
 	}
 
-	var httpExecuter *executer.HTTPExecuter
+	if r.report != nil {
+		if err := r.report.WriteDirectory(r.options.ReportDir); err != nil {
+			gologger.Errorf("Could not write report to '%s': %s\n", r.options.ReportDir, err)
+		}
+	}
+}
 
-	var dnsExecuter *executer.DNSExecuter
+// prepareTemplate creates the job running a template on a target. The requests of the template
+// are executed one after the other. For ordered templates, the values extracted by a request
+// are passed on to the following ones.
+func (r *Runner) prepareTemplate(ctx context.Context, p progress.IProgress, template *templates.Template) scanJob {
+	templateRequests := template.GetRequests()
+	executers := make([]executer.RequestExecuter, len(templateRequests))
 
-	var err error
+	var requestCount int64
 
-	// Create an executer based on the request type.
-	switch value := request.(type) {
-	case *requests.DNSRequest:
-		dnsExecuter = executer.NewDNSExecuter(&executer.DNSOptions{
-			Debug:         r.options.Debug,
-			Template:      template,
-			DNSRequest:    value,
-			Writer:        writer,
-			JSON:          r.options.JSON,
-			JSONRequests:  r.options.JSONRequests,
-			ColoredOutput: !r.options.NoColor,
-			Colorizer:     r.colorizer,
-			Decolorizer:   r.decolorizer,
-		})
-	case *requests.BulkHTTPRequest:
-		httpExecuter, err = executer.NewHTTPExecuter(&executer.HTTPOptions{
-			Debug:           r.options.Debug,
-			Template:        template,
-			BulkHTTPRequest: value,
-			Writer:          writer,
-			Timeout:         r.options.Timeout,
-			Retries:         r.options.Retries,
-			ProxyURL:        r.options.ProxyURL,
-			ProxySocksURL:   r.options.ProxySocksURL,
-			CustomHeaders:   r.options.CustomHeaders,
-			JSON:            r.options.JSON,
-			JSONRequests:    r.options.JSONRequests,
-			CookieReuse:     value.CookieReuse,
-			ColoredOutput:   !r.options.NoColor,
-			Colorizer:       r.colorizer,
-			Decolorizer:     r.decolorizer,
-		})
-	}
+	for i, request := range templateRequests {
+		execute, err := r.makeExecuter(template, request)
+		if err != nil {
+			r.dropRequest(p, request, err)
 
-	if err != nil {
-		p.Drop(request.(*requests.BulkHTTPRequest).GetRequestCount())
-		gologger.Warningf("Could not create http client: %s\n", err)
+			continue
+		}
 
-		return false
+		executers[i] = execute
+		requestCount += getRequestCount(request)
 	}
 
-	var globalresult atomicboolean.AtomBool
+	return func(URL string) bool {
+		// Skip the targets completed by a previous run, and all of them once the scan is interrupted
+		if r.resume.isCompleted(template.ID, URL) || ctx.Err() != nil {
+			p.Drop(requestCount)
 
-	var wg sync.WaitGroup
+			return false
+		}
 
-	scanner := bufio.NewScanner(strings.NewReader(r.input))
-	for scanner.Scan() {
-		text := scanner.Text()
+		var values map[string]interface{}
+		if template.Ordered {
+			values = make(map[string]interface{})
+		}
 
-		r.limiter <- struct{}{}
+		gotResults := false
+		failed := false
 
-		wg.Add(1)
+		for _, execute := range executers {
+			if execute == nil {
+				continue
+			}
 
-		go func(URL string) {
-			defer wg.Done()
+			result := execute(ctx, p, URL, values)
+			gotResults = gotResults || result.GotResults
 
-			var result executer.Result
+			if result.Error != nil {
+				// The requests cancelled by an interrupt are not worth a warning
+				if ctx.Err() == nil {
+					gologger.Warningf("Could not execute step: %s\n", result.Error)
+				}
 
-			if httpExecuter != nil {
-				result = httpExecuter.ExecuteHTTP(ctx, p, URL)
-				globalresult.Or(result.GotResults)
+				failed = true
 			}
 
-			if dnsExecuter != nil {
-				result = dnsExecuter.ExecuteDNS(p, URL)
-				globalresult.Or(result.GotResults)
+			if values != nil {
+				result.ExportValues(values)
 			}
+		}
 
-			if result.Error != nil {
-				gologger.Warningf("Could not execute step: %s\n", result.Error)
-			}
+		// Run the template again on the target when resuming if a request failed
+		if !failed {
+			r.resume.markCompleted(template.ID, URL)
+		}
+
+		return gotResults
+	}
+}
+
+// makeExecuter creates an executer for a request of a template writing to the output of the runner.
+func (r *Runner) makeExecuter(template *templates.Template, request interface{}) (executer.RequestExecuter, error) {
+	return executer.NewRequestExecuter(template, request, &executer.Options{
+		Debug:         r.options.Debug,
+		JSONRequests:  r.includeRequests(),
+		Timeout:       r.options.Timeout,
+		Retries:       r.options.Retries,
+		ProxyURL:      r.options.ProxyURL,
+		ProxySocksURL: r.options.ProxySocksURL,
+		CustomHeaders: r.options.CustomHeaders,
+		Writer:        r.writer,
+		RateLimiter:   r.rateLimiter,
+		HostErrors:    r.hostErrors,
+		ClientPool:    r.clientPool,
+	})
+}
+
+// includeRequests returns whether the results carry the requests and responses, which
+// are needed by the JSON output with requests and by the report directory.
+func (r *Runner) includeRequests() bool {
+	return r.options.JSONRequests || r.options.ReportDir != ""
+}
 
-			<-r.limiter
-		}(text)
+// dropRequest removes a request that could not be executed from the progress
+func (r *Runner) dropRequest(p progress.IProgress, request interface{}, err error) {
+	switch value := request.(type) {
+	case *requests.BulkHTTPRequest:
+		p.Drop(value.GetRequestCount() * r.inputCount)
+		gologger.Warningf("Could not create http client: %s\n", err)
+	case *requests.NetworkRequest:
+		p.Drop(value.GetRequestCount() * r.inputCount)
+		gologger.Warningf("Could not create network client: %s\n", err)
+	case *requests.SSLRequest:
+		p.Drop(value.GetRequestCount() * r.inputCount)
+		gologger.Warningf("Could not create ssl client: %s\n", err)
+	case *requests.WebSocketRequest:
+		p.Drop(value.GetRequestCount() * r.inputCount)
+		gologger.Warningf("Could not create websocket client: %s\n", err)
 	}
+}
 
-	wg.Wait()
+// getRequestCount returns the number of requests a request of a template performs on a target
+func getRequestCount(request interface{}) int64 {
+	if value, ok := request.(interface{ GetRequestCount() int64 }); ok {
+		return value.GetRequestCount()
+	}
 
-	// See if we got any results from the executers
-	return globalresult.Get()
+	return 0
 }
 
-// ProcessWorkflowWithList coming from stdin or list of targets
-func (r *Runner) ProcessWorkflowWithList(p progress.IProgress, workflow *workflows.Workflow) {
+// prepareWorkflow creates the job running a workflow on a target, or
+// nil if the templates of the workflow could not be loaded.
+func (r *Runner) prepareWorkflow(ctx context.Context, p progress.IProgress, workflow *workflows.Workflow) scanJob {
 	workflowTemplatesList, err := r.PreloadTemplates(p, workflow)
 	if err != nil {
 		gologger.Warningf("Could not preload templates for workflow %s: %s\n", workflow.ID, err)
 
-		return
+		return nil
 	}
 
 	logicBytes := []byte(workflow.Logic)
 
-	var wg sync.WaitGroup
-
-	scanner := bufio.NewScanner(strings.NewReader(r.input))
-	for scanner.Scan() {
-		targetURL := scanner.Text()
-		r.limiter <- struct{}{}
+	return func(targetURL string) bool {
+		// Skip the targets completed by a previous run, and all of them once the scan is interrupted
+		if r.resume.isCompleted(workflow.ID, targetURL) || ctx.Err() != nil {
+			return false
+		}
 
-		wg.Add(1)
+		script := tengo.NewScript(logicBytes)
+		script.SetImports(stdlib.GetModuleMap(stdlib.AllModuleNames()...))
 
-		go func(targetURL string) {
-			defer wg.Done()
+		for _, workflowTemplate := range *workflowTemplatesList {
+			err := script.Add(workflowTemplate.Name, &workflows.NucleiVar{Templates: workflowTemplate.Templates, URL: targetURL})
+			if err != nil {
+				gologger.Errorf("Could not initialize script for workflow '%s': %s\n", workflow.ID, err)
 
-			script := tengo.NewScript(logicBytes)
-			script.SetImports(stdlib.GetModuleMap(stdlib.AllModuleNames()...))
+				continue
+			}
+		}
 
-			for _, workflowTemplate := range *workflowTemplatesList {
-				err := script.Add(workflowTemplate.Name, &workflows.NucleiVar{Templates: workflowTemplate.Templates, URL: targetURL})
-				if err != nil {
-					gologger.Errorf("Could not initialize script for workflow '%s': %s\n", workflow.ID, err)
+		_, err := script.RunContext(ctx)
+		if err != nil {
+			gologger.Errorf("Could not execute workflow '%s': %s\n", workflow.ID, err)
 
-					continue
-				}
-			}
+			return false
+		}
 
-			_, err := script.RunContext(context.Background())
-			if err != nil {
-				gologger.Errorf("Could not execute workflow '%s': %s\n", workflow.ID, err)
-			}
+		r.resume.markCompleted(workflow.ID, targetURL)
 
-			<-r.limiter
-		}(targetURL)
+		return false
 	}
-
-	wg.Wait()
 }
 
 // PreloadTemplates preload the workflow templates once
@@ -645,12 +765,6 @@ func (r *Runner) PreloadTemplates(p progress.IProgress, workflow *workflows.Work
 	var wflTemplatesList []WorkflowTemplates
 
 	for name, value := range workflow.Variables {
-		var writer *bufio.Writer
-		if r.output != nil {
-			writer = bufio.NewWriter(r.output)
-			defer writer.Flush()
-		}
-
 		// Check if the template is an absolute path or relative path.
 		// If the path is absolute, use it. Otherwise,
 		if r.isRelative(value) {
@@ -673,34 +787,7 @@ func (r *Runner) PreloadTemplates(p progress.IProgress, workflow *workflows.Work
 				return nil, err
 			}
 
-			template := &workflows.Template{Progress: p}
-			if len(t.BulkRequestsHTTP) > 0 {
-				template.HTTPOptions = &executer.HTTPOptions{
-					Debug:         r.options.Debug,
-					Writer:        writer,
-					Template:      t,
-					Timeout:       r.options.Timeout,
-					Retries:       r.options.Retries,
-					ProxyURL:      r.options.ProxyURL,
-					ProxySocksURL: r.options.ProxySocksURL,
-					CustomHeaders: r.options.CustomHeaders,
-					CookieJar:     jar,
-					ColoredOutput: !r.options.NoColor,
-					Colorizer:     r.colorizer,
-					Decolorizer:   r.decolorizer,
-				}
-			} else if len(t.RequestsDNS) > 0 {
-				template.DNSOptions = &executer.DNSOptions{
-					Debug:         r.options.Debug,
-					Template:      t,
-					Writer:        writer,
-					ColoredOutput: !r.options.NoColor,
-					Colorizer:     r.colorizer,
-					Decolorizer:   r.decolorizer,
-				}
-			}
-
-			if template.DNSOptions != nil || template.HTTPOptions != nil {
+			if template := r.makeWorkflowTemplate(p, t, jar); template != nil {
 				wtlst = append(wtlst, template)
 			}
 		} else {
@@ -734,27 +821,8 @@ func (r *Runner) PreloadTemplates(p progress.IProgress, workflow *workflows.Work
 				if err != nil {
 					return nil, err
 				}
-				template := &workflows.Template{Progress: p}
-				if len(t.BulkRequestsHTTP) > 0 {
-					template.HTTPOptions = &executer.HTTPOptions{
-						Debug:         r.options.Debug,
-						Writer:        writer,
-						Template:      t,
-						Timeout:       r.options.Timeout,
-						Retries:       r.options.Retries,
-						ProxyURL:      r.options.ProxyURL,
-						ProxySocksURL: r.options.ProxySocksURL,
-						CustomHeaders: r.options.CustomHeaders,
-						CookieJar:     jar,
-					}
-				} else if len(t.RequestsDNS) > 0 {
-					template.DNSOptions = &executer.DNSOptions{
-						Debug:    r.options.Debug,
-						Template: t,
-						Writer:   writer,
-					}
-				}
-				if template.DNSOptions != nil || template.HTTPOptions != nil {
+
+				if template := r.makeWorkflowTemplate(p, t, jar); template != nil {
 					wtlst = append(wtlst, template)
 				}
 			}
@@ -766,6 +834,45 @@ func (r *Runner) PreloadTemplates(p progress.IProgress, workflow *workflows.Work
 	return &wflTemplatesList, nil
 }
 
+// makeWorkflowTemplate creates the template run by a workflow, sharing the cookie jar of the
+// workflow if any. It returns nil if the template has neither http nor dns requests.
+func (r *Runner) makeWorkflowTemplate(p progress.IProgress, t *templates.Template, jar *cookiejar.Jar) *workflows.Template {
+	template := &workflows.Template{Progress: p}
+
+	switch {
+	case len(t.BulkRequestsHTTP) > 0:
+		template.HTTPOptions = &executer.HTTPOptions{
+			Debug:         r.options.Debug,
+			Writer:        r.writer,
+			Template:      t,
+			Timeout:       r.options.Timeout,
+			Retries:       r.options.Retries,
+			ProxyURL:      r.options.ProxyURL,
+			ProxySocksURL: r.options.ProxySocksURL,
+			CustomHeaders: r.options.CustomHeaders,
+			JSONRequests:  r.includeRequests(),
+			CookieJar:     jar,
+			RateLimiter:   r.rateLimiter,
+			HostErrors:    r.hostErrors,
+			ClientPool:    r.clientPool,
+		}
+	case len(t.RequestsDNS) > 0:
+		template.DNSOptions = &executer.DNSOptions{
+			Debug:        r.options.Debug,
+			Timeout:      r.options.Timeout,
+			Template:     t,
+			Writer:       r.writer,
+			JSONRequests: r.includeRequests(),
+			RateLimiter:  r.rateLimiter,
+			HostErrors:   r.hostErrors,
+		}
+	default:
+		return nil
+	}
+
+	return template
+}
+
 func (r *Runner) parse(file string) (interface{}, error) {
 	// check if it's a template
 	template, errTemplate := templates.Parse(file)
@@ -0,0 +1,157 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/atomicboolean"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/workflows"
+)
+
+// scanJob runs a template or a workflow on a target and returns whether it got results
+type scanJob func(URL string) bool
+
+// prepareJob creates the job for a template or a workflow, or nil if it can't be run
+func (r *Runner) prepareJob(ctx context.Context, p progress.IProgress, template interface{}) scanJob {
+	switch value := template.(type) {
+	case *templates.Template:
+		return r.prepareTemplate(ctx, p, value)
+	case *workflows.Workflow:
+		return r.prepareWorkflow(ctx, p, value)
+	}
+
+	return nil
+}
+
+// runTemplateFirst runs the templates one after the other on all the targets. Up to
+// template-concurrency templates are run at once, each on up to host-concurrency targets
+// at once. The templates are only prepared when they are about to run.
+func (r *Runner) runTemplateFirst(ctx context.Context, p progress.IProgress, availableTemplates []interface{}) bool {
+	var results atomicboolean.AtomBool
+
+	var wg sync.WaitGroup
+
+	templateLimiter := make(chan struct{}, r.options.TemplateConcurrency)
+
+	for _, t := range availableTemplates {
+		templateLimiter <- struct{}{}
+
+		wg.Add(1)
+
+		go func(template interface{}) {
+			defer wg.Done()
+
+			if job := r.prepareJob(ctx, p, template); job != nil {
+				results.Or(r.runJobWithList(job))
+			}
+
+			<-templateLimiter
+		}(t)
+	}
+
+	wg.Wait()
+
+	return results.Get()
+}
+
+// runJobWithList runs a job on all the targets, up to host-concurrency targets at once
+func (r *Runner) runJobWithList(job scanJob) bool {
+	var results atomicboolean.AtomBool
+
+	var wg sync.WaitGroup
+
+	hostLimiter := make(chan struct{}, r.options.HostConcurrency)
+
+	scanner := bufio.NewScanner(strings.NewReader(r.input))
+	for scanner.Scan() {
+		hostLimiter <- struct{}{}
+		r.limiter <- struct{}{}
+
+		wg.Add(1)
+
+		go func(URL string) {
+			defer wg.Done()
+
+			results.Or(job(URL))
+
+			<-r.limiter
+			<-hostLimiter
+		}(scanner.Text())
+	}
+
+	wg.Wait()
+
+	return results.Get()
+}
+
+// runHostFirst runs all the templates on a target before moving to the next one, so
+// that complete results for each target are available as soon as possible. Up to
+// host-concurrency targets are scanned at once, each with up to template-concurrency
+// templates at once. All the templates are prepared before the scan starts.
+func (r *Runner) runHostFirst(ctx context.Context, p progress.IProgress, availableTemplates []interface{}) bool {
+	jobs := make([]scanJob, 0, len(availableTemplates))
+
+	for _, template := range availableTemplates {
+		if job := r.prepareJob(ctx, p, template); job != nil {
+			jobs = append(jobs, job)
+		}
+	}
+
+	var results atomicboolean.AtomBool
+
+	var wg sync.WaitGroup
+
+	hostLimiter := make(chan struct{}, r.options.HostConcurrency)
+
+	scanner := bufio.NewScanner(strings.NewReader(r.input))
+	for scanner.Scan() {
+		hostLimiter <- struct{}{}
+
+		wg.Add(1)
+
+		go func(URL string) {
+			defer wg.Done()
+
+			results.Or(r.runJobsOnHost(jobs, URL))
+
+			<-hostLimiter
+		}(scanner.Text())
+	}
+
+	wg.Wait()
+
+	return results.Get()
+}
+
+// runJobsOnHost runs all the jobs on a target, up to template-concurrency jobs at once
+func (r *Runner) runJobsOnHost(jobs []scanJob, URL string) bool {
+	var results atomicboolean.AtomBool
+
+	var wg sync.WaitGroup
+
+	templateLimiter := make(chan struct{}, r.options.TemplateConcurrency)
+
+	for _, job := range jobs {
+		templateLimiter <- struct{}{}
+		r.limiter <- struct{}{}
+
+		wg.Add(1)
+
+		go func(job scanJob) {
+			defer wg.Done()
+
+			results.Or(job(URL))
+
+			<-r.limiter
+			<-templateLimiter
+		}(job)
+	}
+
+	wg.Wait()
+
+	return results.Get()
+}
@@ -0,0 +1,3 @@
+// Package engine allows running nuclei templates from
+// other Go programs.
+package engine
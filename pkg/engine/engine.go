@@ -0,0 +1,295 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/karrick/godirwalk"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/executer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/workflows"
+)
+
+// ResultEvent is a result found by a template during a scan
+type ResultEvent = executer.ResultEvent
+
+// Callback is called for each result found during a scan
+type Callback func(event *ResultEvent)
+
+// RequestError is the error of a request of a template on a target during a scan
+type RequestError struct {
+	// Template is the ID of the template
+	Template string
+	// Target is the target the request was sent to
+	Target string
+	// Err is the error of the request
+	Err error
+}
+
+// Error returns the error message
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%s on %s: %s", e.Template, e.Target, e.Err)
+}
+
+// Unwrap returns the error of the request
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// Engine runs templates on targets without touching the terminal, the files or the
+// global state of the process. It is safe for concurrent use, including running
+// several scans of the same targets at the same time.
+type Engine struct {
+	options   *Options
+	templates []*templates.Template
+	mutex     sync.RWMutex
+}
+
+// New creates a new engine with the given options
+func New(options *Options) (*Engine, error) {
+	engineOptions := Options{}
+	if options != nil {
+		engineOptions = *options
+	}
+
+	err := engineOptions.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Engine{options: &engineOptions}, nil
+}
+
+// LoadTemplates loads the templates from files and directories. Directories are
+// walked recursively for yaml files. Workflows are not supported and are skipped
+// when found in a directory.
+func (e *Engine) LoadTemplates(paths ...string) error {
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			err = e.loadTemplateFile(path, false)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		err = godirwalk.Walk(path, &godirwalk.Options{
+			Callback: func(path string, d *godirwalk.Dirent) error {
+				if d.IsDir() || !strings.HasSuffix(path, ".yaml") {
+					return nil
+				}
+
+				return e.loadTemplateFile(path, true)
+			},
+			Unsorted: true,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadTemplateBytes loads a template from memory. Payload files
+// of the template are resolved from the working directory.
+func (e *Engine) LoadTemplateBytes(data []byte) error {
+	template, err := templates.ParseBytes(data, "")
+	if err != nil {
+		return errors.Wrap(err, "could not parse template")
+	}
+
+	e.addTemplate(template)
+
+	return nil
+}
+
+// TemplateCount returns the number of templates loaded in the engine
+func (e *Engine) TemplateCount() int {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	return len(e.templates)
+}
+
+// loadTemplateFile loads a single template file in the engine
+func (e *Engine) loadTemplateFile(path string, skipWorkflows bool) error {
+	template, err := templates.Parse(path)
+	if err != nil {
+		if _, workflowErr := workflows.Parse(path); workflowErr == nil {
+			if skipWorkflows {
+				return nil
+			}
+
+			return errors.Errorf("could not load %s: workflows are not supported", path)
+		}
+
+		return errors.Wrapf(err, "could not parse template %s", path)
+	}
+
+	e.addTemplate(template)
+
+	return nil
+}
+
+func (e *Engine) addTemplate(template *templates.Template) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.templates = append(e.templates, template)
+}
+
+// Scan runs the loaded templates on the targets and calls the callback for each
+// result found. The errors of the requests are passed to the OnError option, if
+// set. The callbacks are never called concurrently. Scan returns once all the
+// requests are done, or with the context error if it is cancelled.
+func (e *Engine) Scan(ctx context.Context, targets []string, callback Callback) error {
+	if callback == nil {
+		return errors.New("no callback provided")
+	}
+
+	e.mutex.RLock()
+	loaded := make([]*templates.Template, len(e.templates))
+	copy(loaded, e.templates)
+	e.mutex.RUnlock()
+
+	writer := &callbackWriter{callback: callback, onError: e.options.OnError}
+
+	executerOptions := &executer.Options{
+		JSONRequests:  e.options.IncludeRequests,
+		Timeout:       e.options.Timeout,
+		Retries:       e.options.Retries,
+		ProxyURL:      e.options.ProxyURL,
+		ProxySocksURL: e.options.ProxySocksURL,
+		CustomHeaders: requests.CustomHeaders(e.options.CustomHeaders),
+		Writer:        writer,
+		Logger:        e.options.Logger,
+		ClientPool:    executer.NewHTTPClientPool(nil),
+	}
+
+	p := &progress.NoOpProgress{}
+	limiter := make(chan struct{}, e.options.Concurrency)
+
+	var wg sync.WaitGroup
+
+	// run executes a function once a slot is available. It returns
+	// false without running the function if the context is cancelled.
+	run := func(fn func()) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case limiter <- struct{}{}:
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			fn()
+			<-limiter
+		}()
+
+		return true
+	}
+
+	for _, template := range loaded {
+		var executers []executer.RequestExecuter
+
+		for _, request := range template.GetRequests() {
+			execute, err := executer.NewRequestExecuter(template, request, executerOptions)
+			if err != nil {
+				wg.Wait()
+
+				return errors.Wrapf(err, "could not create executer for template %s", template.ID)
+			}
+
+			executers = append(executers, execute)
+		}
+
+		for _, target := range targets {
+			target := target
+
+			if template.Ordered {
+				if !run(func() {
+					values := make(map[string]interface{})
+
+					for _, execute := range executers {
+						result := execute(ctx, p, target, values)
+						writer.writeError(template.ID, target, result.Error)
+						result.ExportValues(values)
+					}
+				}) {
+					wg.Wait()
+
+					return ctx.Err()
+				}
+
+				continue
+			}
+
+			for _, execute := range executers {
+				execute := execute
+
+				if !run(func() {
+					result := execute(ctx, p, target, nil)
+					writer.writeError(template.ID, target, result.Error)
+				}) {
+					wg.Wait()
+
+					return ctx.Err()
+				}
+			}
+		}
+	}
+
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// callbackWriter is an output writer delivering the results and the errors to callbacks
+type callbackWriter struct {
+	callback Callback
+	onError  ErrorCallback
+	mutex    sync.Mutex
+}
+
+// Write calls the callback with the result event
+func (w *callbackWriter) Write(event *executer.ResultEvent) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.callback(event)
+
+	return nil
+}
+
+// writeError calls the error callback with the error of a request, if any
+func (w *callbackWriter) writeError(template, target string, err error) {
+	if err == nil || w.onError == nil {
+		return
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.onError(&RequestError{Template: template, Target: target, Err: err})
+}
+
+// Close does nothing as there is no output to flush
+func (w *callbackWriter) Close() error {
+	return nil
+}
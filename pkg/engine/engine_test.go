@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testTemplate = `id: engine-test
+info:
+  name: Engine Test
+  author: test
+  severity: info
+requests:
+  - method: GET
+    path:
+      - "{{BaseURL}}/admin"
+    matchers:
+      - type: word
+        name: panel
+        words:
+          - "admin panel"
+    extractors:
+      - type: regex
+        regex:
+          - "version [0-9.]+"
+`
+
+// testLogger records the verbose messages of the requests
+type testLogger struct {
+	messages []string
+	mutex    sync.Mutex
+}
+
+func (l *testLogger) Verbosef(format string, label string, args ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+func (l *testLogger) Infof(format string, args ...interface{}) {}
+
+func (l *testLogger) Warningf(format string, args ...interface{}) {}
+
+func (l *testLogger) Errorf(format string, args ...interface{}) {}
+
+func TestScan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/admin" {
+			http.NotFound(w, r)
+			return
+		}
+
+		_, _ = w.Write([]byte("admin panel, version 1.2.3"))
+	}))
+	defer server.Close()
+
+	logger := &testLogger{}
+
+	engine, err := New(&Options{Logger: logger})
+	require.Nil(t, err, "Could not create engine")
+
+	err = engine.LoadTemplateBytes([]byte(testTemplate))
+	require.Nil(t, err, "Could not load template")
+	require.Equal(t, 1, engine.TemplateCount(), "Could not count loaded templates")
+
+	var events []*ResultEvent
+
+	err = engine.Scan(context.Background(), []string{server.URL}, func(event *ResultEvent) {
+		events = append(events, event)
+	})
+	require.Nil(t, err, "Could not scan target")
+	require.Len(t, events, 2, "Could not get matcher and extractor result events")
+
+	for _, event := range events {
+		require.Equal(t, "engine-test", event.Template, "Could not get template of event")
+		require.Equal(t, "info", event.Severity, "Could not get severity of event")
+		require.Equal(t, "http", event.Type, "Could not get type of event")
+		require.Equal(t, server.URL, event.Host, "Could not get host of event")
+		require.Equal(t, server.URL+"/admin", event.Matched, "Could not get matched url of event")
+		require.Empty(t, event.Request, "Could include request without option")
+	}
+
+	require.Equal(t, "panel", events[0].MatcherName, "Could not get matcher of event")
+	require.Equal(t, []string{"version 1.2.3"}, events[1].ExtractedResults, "Could not get extracted results of event")
+
+	require.Equal(t, []string{fmt.Sprintf("Sent HTTP request to %s\n", server.URL)}, logger.messages, "Could not log to engine logger")
+}
+
+func TestScanErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	var errs []*RequestError
+
+	engine, err := New(&Options{OnError: func(err *RequestError) { errs = append(errs, err) }})
+	require.Nil(t, err, "Could not create engine")
+
+	err = engine.LoadTemplateBytes([]byte(testTemplate))
+	require.Nil(t, err, "Could not load template")
+
+	err = engine.Scan(context.Background(), []string{server.URL}, func(event *ResultEvent) {
+		t.Errorf("Could get result event from closed server: %v", event)
+	})
+	require.Nil(t, err, "Could not scan target")
+	require.Len(t, errs, 1, "Could not get request error")
+	require.Equal(t, "engine-test", errs[0].Template, "Could not get template of request error")
+	require.Equal(t, server.URL, errs[0].Target, "Could not get target of request error")
+
+	err = engine.Scan(context.Background(), []string{server.URL}, nil)
+	require.NotNil(t, err, "Could scan without callback")
+}
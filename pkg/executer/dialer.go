@@ -0,0 +1,47 @@
+package executer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// contextDialer is a dialer that supports dialing with a context.
+type contextDialer interface {
+	DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// newDialer creates a dialer for raw connections, using a socks proxy if specified
+func newDialer(timeout time.Duration, proxySocksURL string) (contextDialer, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+
+	if proxySocksURL == "" {
+		return dialer, nil
+	}
+
+	// Attempts to overwrite the dialer with the socks proxied version
+	socksURL, err := url.Parse(proxySocksURL)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyAuth := &proxy.Auth{}
+	proxyAuth.User = socksURL.User.Username()
+	proxyAuth.Password, _ = socksURL.User.Password()
+
+	socksDialer, err := proxy.SOCKS5("tcp", fmt.Sprintf("%s:%s", socksURL.Hostname(), socksURL.Port()), proxyAuth, dialer)
+	if err != nil {
+		return nil, err
+	}
+
+	dc, ok := socksDialer.(contextDialer)
+	if !ok {
+		return nil, fmt.Errorf("socks dialer does not support contexts")
+	}
+
+	return dc, nil
+}
@@ -28,3 +28,13 @@ func extractDomain(theURL string) string {
 
 	return hostname
 }
+
+// extractHost extracts the host of a URL, including the port if any
+func extractHost(theURL string) string {
+	u, err := url.Parse(theURL)
+	if err != nil {
+		return ""
+	}
+
+	return u.Host
+}
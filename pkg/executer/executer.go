@@ -0,0 +1,155 @@
+package executer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/hosterrors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/ratelimit"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// Options contains the configuration options shared by all the executers.
+type Options struct {
+	Debug         bool
+	JSONRequests  bool
+	Timeout       int
+	Retries       int
+	ProxyURL      string
+	ProxySocksURL string
+	CustomHeaders requests.CustomHeaders
+	Writer        OutputWriter
+	Logger        Logger
+	RateLimiter   *ratelimit.Limiter
+	HostErrors    *hosterrors.Cache
+	ClientPool    *HTTPClientPool
+}
+
+// RequestExecuter executes a single request of a template on a target. The values
+// are variables extracted by previous requests of the template, if any.
+type RequestExecuter func(ctx context.Context, p progress.IProgress, URL string, values map[string]interface{}) Result
+
+// NewRequestExecuter creates an executer for a request of a template based on the request type.
+func NewRequestExecuter(template *templates.Template, request interface{}, options *Options) (RequestExecuter, error) {
+	switch value := request.(type) {
+	case *requests.DNSRequest:
+		dnsExecuter := NewDNSExecuter(&DNSOptions{
+			Debug:        options.Debug,
+			Timeout:      options.Timeout,
+			Template:     template,
+			DNSRequest:   value,
+			Writer:       options.Writer,
+			Logger:       options.Logger,
+			JSONRequests: options.JSONRequests,
+			RateLimiter:  options.RateLimiter,
+			HostErrors:   options.HostErrors,
+		})
+
+		return func(ctx context.Context, p progress.IProgress, URL string, values map[string]interface{}) Result {
+			return dnsExecuter.ExecuteDNS(p, URL, values)
+		}, nil
+	case *requests.BulkHTTPRequest:
+		httpExecuter, err := NewHTTPExecuter(&HTTPOptions{
+			Debug:           options.Debug,
+			Template:        template,
+			BulkHTTPRequest: value,
+			Writer:          options.Writer,
+			Logger:          options.Logger,
+			Timeout:         options.Timeout,
+			Retries:         options.Retries,
+			ProxyURL:        options.ProxyURL,
+			ProxySocksURL:   options.ProxySocksURL,
+			CustomHeaders:   options.CustomHeaders,
+			JSONRequests:    options.JSONRequests,
+			CookieReuse:     value.CookieReuse,
+			RateLimiter:     options.RateLimiter,
+			HostErrors:      options.HostErrors,
+			ClientPool:      options.ClientPool,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return httpExecuter.ExecuteHTTP, nil
+	case *requests.NetworkRequest:
+		networkExecuter, err := NewNetworkExecuter(&NetworkOptions{
+			Debug:          options.Debug,
+			Template:       template,
+			NetworkRequest: value,
+			Writer:         options.Writer,
+			Logger:         options.Logger,
+			Timeout:        options.Timeout,
+			Retries:        options.Retries,
+			ProxySocksURL:  options.ProxySocksURL,
+			JSONRequests:   options.JSONRequests,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return networkExecuter.ExecuteNetwork, nil
+	case *requests.SSLRequest:
+		sslExecuter, err := NewSSLExecuter(&SSLOptions{
+			Debug:         options.Debug,
+			Template:      template,
+			SSLRequest:    value,
+			Writer:        options.Writer,
+			Logger:        options.Logger,
+			Timeout:       options.Timeout,
+			Retries:       options.Retries,
+			ProxySocksURL: options.ProxySocksURL,
+			JSONRequests:  options.JSONRequests,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return sslExecuter.ExecuteSSL, nil
+	case *requests.FileRequest:
+		fileExecuter := NewFileExecuter(&FileOptions{
+			Debug:        options.Debug,
+			Template:     template,
+			FileRequest:  value,
+			Writer:       options.Writer,
+			Logger:       options.Logger,
+			JSONRequests: options.JSONRequests,
+		})
+
+		return func(ctx context.Context, p progress.IProgress, URL string, values map[string]interface{}) Result {
+			return fileExecuter.ExecuteFile(p, URL, values)
+		}, nil
+	case *requests.WebSocketRequest:
+		websocketExecuter, err := NewWebSocketExecuter(&WebSocketOptions{
+			Debug:            options.Debug,
+			Template:         template,
+			WebSocketRequest: value,
+			Writer:           options.Writer,
+			Logger:           options.Logger,
+			Timeout:          options.Timeout,
+			Retries:          options.Retries,
+			ProxyURL:         options.ProxyURL,
+			ProxySocksURL:    options.ProxySocksURL,
+			CustomHeaders:    options.CustomHeaders,
+			JSONRequests:     options.JSONRequests,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return websocketExecuter.ExecuteWebSocket, nil
+	}
+
+	return nil, fmt.Errorf("unknown request type %T", request)
+}
+
+// ExportValues adds the first value of each named extractor of the result
+// to the values passed on to the following requests of an ordered template.
+func (r *Result) ExportValues(values map[string]interface{}) {
+	for name, extracted := range r.Extractions {
+		if list, ok := extracted.([]string); ok && name != "" && len(list) > 0 {
+			values[name] = list[0]
+		}
+	}
+}
@@ -1,17 +1,17 @@
 package executer
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-	"regexp"
-	"sync"
+	"time"
 
-	"github.com/logrusorgru/aurora"
+	"github.com/miekg/dns"
 	"github.com/pkg/errors"
-	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/hosterrors"
 	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/ratelimit"
 	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
 	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
 	retryabledns "github.com/projectdiscovery/retryabledns"
@@ -20,19 +20,18 @@ import (
 // DNSExecuter is a client for performing a DNS request
 // for a template.
 type DNSExecuter struct {
-	coloredOutput bool
-	debug         bool
-	jsonOutput    bool
-	jsonRequest   bool
-	Results       bool
-	dnsClient     *retryabledns.Client
-	template      *templates.Template
-	dnsRequest    *requests.DNSRequest
-	writer        *bufio.Writer
-	outputMutex   *sync.Mutex
-
-	colorizer   aurora.Aurora
-	decolorizer *regexp.Regexp
+	debug       bool
+	jsonRequest bool
+	Results     bool
+	dnsClient   *retryabledns.Client
+	client      *dns.Client
+	timeout     time.Duration
+	template    *templates.Template
+	dnsRequest  *requests.DNSRequest
+	writer      OutputWriter
+	logger      Logger
+	rateLimiter *ratelimit.Limiter
+	hostErrors  *hosterrors.Cache
 }
 
 // DefaultResolvers contains the list of resolvers known to be trusted.
@@ -45,42 +44,52 @@ var DefaultResolvers = []string{
 
 // DNSOptions contains configuration options for the DNS executer.
 type DNSOptions struct {
-	ColoredOutput bool
-	Debug         bool
-	JSON          bool
-	JSONRequests  bool
-	Template      *templates.Template
-	DNSRequest    *requests.DNSRequest
-	Writer        *bufio.Writer
-
-	Colorizer   aurora.Aurora
-	Decolorizer *regexp.Regexp
+	Debug        bool
+	JSONRequests bool
+	Timeout      int
+	Template     *templates.Template
+	DNSRequest   *requests.DNSRequest
+	Writer       OutputWriter
+	Logger       Logger
+	RateLimiter  *ratelimit.Limiter
+	HostErrors   *hosterrors.Cache
 }
 
 // NewDNSExecuter creates a new DNS executer from a template
 // and a DNS request query.
 func NewDNSExecuter(options *DNSOptions) *DNSExecuter {
-	dnsClient := retryabledns.New(DefaultResolvers, options.DNSRequest.Retries)
+	dnsClient := retryabledns.New(DefaultResolvers, options.DNSRequest.GetRetries())
+	timeout := time.Duration(options.Timeout) * time.Second
+
+	// The client used for the tcp queries and the queries sent to the authoritative nameservers
+	client := &dns.Client{Net: "udp", Timeout: timeout}
+	if options.DNSRequest.IsTCP() {
+		client.Net = "tcp"
+	}
 
 	executer := &DNSExecuter{
-		debug:         options.Debug,
-		jsonOutput:    options.JSON,
-		jsonRequest:   options.JSONRequests,
-		dnsClient:     dnsClient,
-		template:      options.Template,
-		dnsRequest:    options.DNSRequest,
-		writer:        options.Writer,
-		outputMutex:   &sync.Mutex{},
-		coloredOutput: options.ColoredOutput,
-		colorizer:     options.Colorizer,
-		decolorizer:   options.Decolorizer,
+		debug:       options.Debug,
+		jsonRequest: options.JSONRequests,
+		dnsClient:   dnsClient,
+		client:      client,
+		timeout:     timeout,
+		template:    options.Template,
+		dnsRequest:  options.DNSRequest,
+		writer:      options.Writer,
+		logger:      loggerOrDefault(options.Logger),
+		rateLimiter: options.RateLimiter,
+		hostErrors:  options.HostErrors,
 	}
 
 	return executer
 }
 
-// ExecuteDNS executes the DNS request on a URL
-func (e *DNSExecuter) ExecuteDNS(p progress.IProgress, reqURL string) (result Result) {
+// ExecuteDNS executes the DNS request on a URL. The values are variables
+// extracted by previous requests of the template, if any.
+func (e *DNSExecuter) ExecuteDNS(p progress.IProgress, reqURL string, values map[string]interface{}) (result Result) {
+	result.Matches = make(map[string]interface{})
+	result.Extractions = make(map[string]interface{})
+
 	// Parse the URL and return domain if URL.
 	var domain string
 	if isURL(reqURL) {
@@ -89,8 +98,15 @@ func (e *DNSExecuter) ExecuteDNS(p progress.IProgress, reqURL string) (result Re
 		domain = reqURL
 	}
 
+	// Skip the request if the domain stopped responding
+	if e.hostErrors.IsDead(domain) {
+		p.Drop(1)
+
+		return
+	}
+
 	// Compile each request for the template based on the URL
-	compiledRequest, err := e.dnsRequest.MakeDNSRequest(domain)
+	compiledRequest, err := e.dnsRequest.MakeDNSRequest(domain, values)
 	if err != nil {
 		result.Error = errors.Wrap(err, "could not make dns request")
 
@@ -100,13 +116,25 @@ func (e *DNSExecuter) ExecuteDNS(p progress.IProgress, reqURL string) (result Re
 	}
 
 	if e.debug {
-		gologger.Infof("Dumped DNS request for %s (%s)\n\n", reqURL, e.template.ID)
+		e.logger.Infof("Dumped DNS request for %s (%s)\n\n", reqURL, e.template.ID)
 		fmt.Fprintf(os.Stderr, "%s\n", compiledRequest.String())
 	}
 
+	e.rateLimiter.Take(domain)
+
 	// Send the request to the target servers
-	resp, err := e.dnsClient.Do(compiledRequest)
+	resp, err := e.send(compiledRequest)
+	if err == nil && resp == nil {
+		err = errors.New("no response received")
+	}
+
 	if err != nil {
+		// The resolvers failing doesn't mean the domain stopped responding, only
+		// the errors of its authoritative nameservers are counted.
+		if e.dnsRequest.IsAuthoritative() {
+			e.hostErrors.MarkFailed(domain, err)
+		}
+
 		result.Error = errors.Wrap(err, "could not send dns request")
 
 		p.Drop(1)
@@ -114,12 +142,13 @@ func (e *DNSExecuter) ExecuteDNS(p progress.IProgress, reqURL string) (result Re
 		return
 	}
 
+	e.hostErrors.MarkSuccess(domain)
 	p.Update()
 
-	gologger.Verbosef("Sent DNS request to %s\n", "dns-request", reqURL)
+	e.logger.Verbosef("Sent DNS request to %s\n", "dns-request", reqURL)
 
 	if e.debug {
-		gologger.Infof("Dumped DNS response for %s (%s)\n\n", reqURL, e.template.ID)
+		e.logger.Infof("Dumped DNS response for %s (%s)\n\n", reqURL, e.template.ID)
 		fmt.Fprintf(os.Stderr, "%s\n", resp.String())
 	}
 
@@ -136,7 +165,7 @@ func (e *DNSExecuter) ExecuteDNS(p progress.IProgress, reqURL string) (result Re
 			// If the matcher has matched, and its an OR
 			// write the first output then move to next matcher.
 			if matcherCondition == matchers.ORCondition && len(e.dnsRequest.Extractors) == 0 {
-				e.writeOutputDNS(domain, compiledRequest, resp, matcher, nil)
+				e.writeOutputDNS(reqURL, domain, compiledRequest, resp, matcher, nil)
 				result.GotResults = true
 			}
 		}
@@ -146,28 +175,34 @@ func (e *DNSExecuter) ExecuteDNS(p progress.IProgress, reqURL string) (result Re
 	// next task which is extraction of input from matchers.
 	var extractorResults []string
 
+	// The values extracted by the previous extractors are available to dsl extractors
+	extractedValues := generators.CopyMap(values)
+
 	for _, extractor := range e.dnsRequest.Extractors {
-		for match := range extractor.ExtractDNS(resp) {
+		var extracted []string
+
+		for match := range extractor.ExtractDNS(resp, extractedValues) {
+			if _, ok := extractedValues[extractor.Name]; !ok {
+				extractedValues[extractor.Name] = match
+			}
+
+			extracted = append(extracted, match)
+
 			if !extractor.Internal {
 				extractorResults = append(extractorResults, match)
 			}
 		}
+
+		result.Extractions[extractor.Name] = extracted
 	}
 
 	// Write a final string of output if matcher type is
 	// AND or if we have extractors for the mechanism too.
 	if len(e.dnsRequest.Extractors) > 0 || matcherCondition == matchers.ANDCondition {
-		e.writeOutputDNS(domain, compiledRequest, resp, nil, extractorResults)
+		e.writeOutputDNS(reqURL, domain, compiledRequest, resp, nil, extractorResults)
 
 		result.GotResults = true
 	}
 
 	return result
 }
-
-// Close closes the dns executer for a template.
-func (e *DNSExecuter) Close() {
-	e.outputMutex.Lock()
-	defer e.outputMutex.Unlock()
-	e.writer.Flush()
-}
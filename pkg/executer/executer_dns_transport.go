@@ -0,0 +1,110 @@
+package executer
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// send sends a dns request to the resolvers, or to the authoritative nameservers of the
+// name queried if required by the request. Queries are sent over udp unless tcp is
+// required by the request, zone transfers being always performed over tcp.
+func (e *DNSExecuter) send(request *dns.Msg) (*dns.Msg, error) {
+	if !e.dnsRequest.IsAuthoritative() && !e.dnsRequest.IsTCP() {
+		return e.dnsClient.Do(request)
+	}
+
+	servers := DefaultResolvers
+
+	if e.dnsRequest.IsAuthoritative() {
+		nameservers, err := e.nameservers(request.Question[0].Name)
+		if err != nil {
+			return nil, err
+		}
+
+		servers = nameservers
+	}
+
+	var err error
+
+	for i := 0; i < e.dnsRequest.GetRetries(); i++ {
+		for _, server := range servers {
+			var resp *dns.Msg
+
+			if e.dnsRequest.IsZoneTransfer() {
+				resp, err = e.transfer(request, server)
+			} else {
+				resp, _, err = e.client.Exchange(request, server)
+			}
+
+			if err == nil {
+				return resp, nil
+			}
+		}
+	}
+
+	return nil, err
+}
+
+// nameservers returns the addresses of the authoritative nameservers of the zone of a
+// name, found by asking the resolvers for the NS records of the name and its parents.
+func (e *DNSExecuter) nameservers(name string) ([]string, error) {
+	for labels := dns.SplitDomainName(name); len(labels) > 0; labels = labels[1:] {
+		query := new(dns.Msg)
+		query.SetQuestion(dns.Fqdn(strings.Join(labels, ".")), dns.TypeNS)
+
+		resp, err := e.dnsClient.Do(query)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp == nil {
+			return nil, fmt.Errorf("no response received for the nameservers of %s", name)
+		}
+
+		var servers []string
+
+		for _, record := range resp.Answer {
+			if ns, ok := record.(*dns.NS); ok {
+				servers = append(servers, net.JoinHostPort(strings.TrimSuffix(ns.Ns, "."), "53"))
+			}
+		}
+
+		if len(servers) > 0 {
+			return servers, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no authoritative nameservers found for %s", name)
+}
+
+// transfer performs a zone transfer, returning all the records received
+// as the answer of a single response.
+func (e *DNSExecuter) transfer(request *dns.Msg, server string) (*dns.Msg, error) {
+	transfer := &dns.Transfer{DialTimeout: e.timeout, ReadTimeout: e.timeout}
+
+	envelopes, err := transfer.In(request, server)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(dns.Msg)
+	resp.SetReply(request)
+
+	for envelope := range envelopes {
+		if envelope.Error != nil {
+			err = envelope.Error
+			continue
+		}
+
+		resp.Answer = append(resp.Answer, envelope.RR...)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
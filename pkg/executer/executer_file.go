@@ -0,0 +1,241 @@
+package executer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/karrick/godirwalk"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// FileExecuter is a client for scanning local files
+// for a template.
+type FileExecuter struct {
+	debug       bool
+	jsonRequest bool
+	Results     bool
+	template    *templates.Template
+	fileRequest *requests.FileRequest
+	writer      OutputWriter
+	logger      Logger
+}
+
+// FileOptions contains configuration options for the file executer.
+type FileOptions struct {
+	Debug        bool
+	JSONRequests bool
+	Template     *templates.Template
+	FileRequest  *requests.FileRequest
+	Writer       OutputWriter
+	Logger       Logger
+}
+
+// NewFileExecuter creates a new file executer from a template
+// and a file request query.
+func NewFileExecuter(options *FileOptions) *FileExecuter {
+	executer := &FileExecuter{
+		debug:       options.Debug,
+		jsonRequest: options.JSONRequests,
+		template:    options.Template,
+		fileRequest: options.FileRequest,
+		writer:      options.Writer,
+		logger:      loggerOrDefault(options.Logger),
+	}
+
+	return executer
+}
+
+// ExecuteFile executes the file request on a file or a directory. The values are
+// variables extracted by previous requests of the template, if any.
+func (e *FileExecuter) ExecuteFile(p progress.IProgress, input string, values map[string]interface{}) (result Result) {
+	result.Matches = make(map[string]interface{})
+	result.Extractions = make(map[string]interface{})
+
+	info, err := os.Stat(input)
+	if err != nil {
+		result.Error = errors.Wrap(err, "could not stat input")
+
+		p.Drop(1)
+
+		return
+	}
+
+	switch {
+	case !info.IsDir():
+		err = e.handleFile(input, input, values, &result)
+	case e.fileRequest.NoRecursive:
+		err = e.handleDirectory(input, values, &result)
+	default:
+		err = godirwalk.Walk(input, &godirwalk.Options{
+			Callback: func(path string, d *godirwalk.Dirent) error {
+				if d.IsDir() {
+					return nil
+				}
+
+				if err := e.handleFile(input, path, values, &result); err != nil {
+					e.logger.Verbosef("Could not scan file %s: %s\n", "file-request", path, err)
+				}
+
+				return nil
+			},
+			ErrorCallback: func(path string, err error) godirwalk.ErrorAction {
+				return godirwalk.SkipNode
+			},
+			Unsorted: true,
+		})
+	}
+
+	if err != nil {
+		result.Error = errors.Wrap(err, "could not scan input")
+
+		p.Drop(1)
+
+		return
+	}
+
+	p.Update()
+
+	e.logger.Verbosef("Scanned files in %s\n", "file-request", input)
+
+	return result
+}
+
+// handleDirectory scans the files of a directory without descending into subdirectories
+func (e *FileExecuter) handleDirectory(input string, values map[string]interface{}, result *Result) error {
+	infos, err := ioutil.ReadDir(input)
+	if err != nil {
+		return err
+	}
+
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(input, info.Name())
+
+		if err := e.handleFile(input, path, values, result); err != nil {
+			e.logger.Verbosef("Could not scan file %s: %s\n", "file-request", path, err)
+		}
+	}
+
+	return nil
+}
+
+// handleFile runs the matchers and the extractors of the request on a single file
+func (e *FileExecuter) handleFile(input, path string, values map[string]interface{}, result *Result) error {
+	if !e.fileRequest.ShouldScan(path) {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() || info.Size() > e.fileRequest.GetMaxSize() {
+		return nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "could not read file")
+	}
+
+	data := unsafeToString(content)
+
+	if e.debug {
+		e.logger.Infof("Dumped file content for %s (%s)\n\n", path, e.template.ID)
+		fmt.Fprintf(os.Stderr, "%s\n", data)
+	}
+
+	var offsets []int
+
+	matcherCondition := e.fileRequest.GetMatchersCondition()
+
+	for _, matcher := range e.fileRequest.Matchers {
+		// Check if the matcher matched
+		if !matcher.MatchFile(path, data) {
+			// If the condition is AND we haven't matched, return.
+			if matcherCondition == matchers.ANDCondition {
+				return nil
+			}
+		} else {
+			matcherOffsets := matcher.Locate(data)
+			offsets = append(offsets, matcherOffsets...)
+
+			// If the matcher has matched, and its an OR
+			// write the first output then move to next matcher.
+			if matcherCondition == matchers.ORCondition && len(e.fileRequest.Extractors) == 0 {
+				result.Matches[matcher.Name] = nil
+				e.writeOutputFile(input, fileLocation(path, data, matcherOffsets), data, matcher, nil)
+				result.GotResults = true
+			}
+		}
+	}
+
+	// All matchers have successfully completed so now start with the
+	// next task which is extraction of input from matchers.
+	var extractorResults []string
+
+	// The values extracted by the previous extractors are available to dsl extractors
+	extractedValues := generators.CopyMap(values)
+
+	for _, extractor := range e.fileRequest.Extractors {
+		// The values extracted from the previous files of the input come first
+		extracted, _ := result.Extractions[extractor.Name].([]string)
+
+		for match := range extractor.ExtractFile(path, data, extractedValues) {
+			if _, ok := extractedValues[extractor.Name]; !ok {
+				extractedValues[extractor.Name] = match
+			}
+
+			extracted = append(extracted, match)
+
+			if !extractor.Internal {
+				extractorResults = append(extractorResults, match)
+			}
+
+			if index := strings.Index(data, match); index >= 0 {
+				offsets = append(offsets, index)
+			}
+		}
+
+		result.Extractions[extractor.Name] = extracted
+	}
+
+	// Write a final string of output if matcher type is
+	// AND or if we have extracted results from the file.
+	if len(extractorResults) > 0 || (matcherCondition == matchers.ANDCondition && len(e.fileRequest.Matchers) > 0) {
+		e.writeOutputFile(input, fileLocation(path, data, offsets), data, nil, extractorResults)
+
+		result.GotResults = true
+	}
+
+	return nil
+}
+
+// fileLocation returns the path of the file along with the line number
+// of the first matched offset, if any.
+func fileLocation(path, data string, offsets []int) string {
+	if len(offsets) == 0 {
+		return path
+	}
+
+	first := offsets[0]
+	for _, offset := range offsets[1:] {
+		if offset < first {
+			first = offset
+		}
+	}
+
+	return fmt.Sprintf("%s:%d", path, strings.Count(data[:first], "\n")+1)
+}
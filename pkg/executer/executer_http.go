@@ -1,33 +1,27 @@
 package executer
 
 import (
-	"bufio"
 	"context"
-	"crypto/tls"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"net"
 	"net/http"
 	"net/http/cookiejar"
 	"net/http/httputil"
-	"net/url"
 	"os"
-	"regexp"
 	"strings"
-	"sync"
 	"time"
 
-	"github.com/logrusorgru/aurora"
-
 	"github.com/pkg/errors"
-	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/hosterrors"
 	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/ratelimit"
+	"github.com/projectdiscovery/nuclei/v2/pkg/rawhttp"
 	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
 	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
 	"github.com/projectdiscovery/retryablehttp-go"
-	"golang.org/x/net/proxy"
 )
 
 const (
@@ -38,108 +32,144 @@ const (
 // HTTPExecuter is client for performing HTTP requests
 // for a template.
 type HTTPExecuter struct {
-	coloredOutput   bool
 	debug           bool
 	Results         bool
-	jsonOutput      bool
 	jsonRequest     bool
 	httpClient      *retryablehttp.Client
 	template        *templates.Template
 	bulkHTTPRequest *requests.BulkHTTPRequest
-	writer          *bufio.Writer
-	outputMutex     *sync.Mutex
+	writer          OutputWriter
+	logger          Logger
 	customHeaders   requests.CustomHeaders
 	CookieJar       *cookiejar.Jar
-
-	colorizer   aurora.Aurora
-	decolorizer *regexp.Regexp
+	rateLimiter     *ratelimit.Limiter
+	hostErrors      *hosterrors.Cache
+	rawOptions      *rawhttp.Options
+	proxyURL        string
 }
 
 // HTTPOptions contains configuration options for the HTTP executer.
 type HTTPOptions struct {
 	Debug           bool
-	JSON            bool
 	JSONRequests    bool
 	CookieReuse     bool
-	ColoredOutput   bool
 	Template        *templates.Template
 	BulkHTTPRequest *requests.BulkHTTPRequest
-	Writer          *bufio.Writer
+	Writer          OutputWriter
+	Logger          Logger
 	Timeout         int
 	Retries         int
 	ProxyURL        string
 	ProxySocksURL   string
 	CustomHeaders   requests.CustomHeaders
 	CookieJar       *cookiejar.Jar
-	Colorizer       aurora.Aurora
-	Decolorizer     *regexp.Regexp
+	RateLimiter     *ratelimit.Limiter
+	HostErrors      *hosterrors.Cache
+	ClientPool      *HTTPClientPool
 }
 
 // NewHTTPExecuter creates a new HTTP executer from a template
 // and a HTTP request query.
 func NewHTTPExecuter(options *HTTPOptions) (*HTTPExecuter, error) {
-	var proxyURL *url.URL
+	var jar http.CookieJar
 
-	var err error
+	if options.CookieJar != nil {
+		jar = options.CookieJar
+	} else if options.CookieReuse {
+		cookieJar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, err
+		}
+
+		jar = cookieJar
+	}
 
-	if options.ProxyURL != "" {
-		proxyURL, err = url.Parse(options.ProxyURL)
+	pool := options.ClientPool
+	if pool == nil {
+		pool = NewHTTPClientPool(nil)
 	}
 
+	// Get the HTTP Client
+	client, err := pool.Get(options, jar)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create the HTTP Client
-	client := makeHTTPClient(proxyURL, options)
-	// nolint:bodyclose // false positive there is no body to close yet
-	client.CheckRetry = retryablehttp.HostSprayRetryPolicy()
-
-	if options.CookieJar != nil {
-		client.HTTPClient.Jar = options.CookieJar
-	} else if options.CookieReuse {
-		jar, err := cookiejar.New(nil)
-		if err != nil {
-			return nil, err
-		}
-		client.HTTPClient.Jar = jar
+	// Create the dialer used for the requests sent over raw connections
+	dialer, err := newDialer(time.Duration(options.Timeout)*time.Second, options.ProxySocksURL)
+	if err != nil {
+		return nil, err
 	}
 
 	executer := &HTTPExecuter{
 		debug:           options.Debug,
-		jsonOutput:      options.JSON,
 		jsonRequest:     options.JSONRequests,
 		httpClient:      client,
 		template:        options.Template,
 		bulkHTTPRequest: options.BulkHTTPRequest,
-		outputMutex:     &sync.Mutex{},
 		writer:          options.Writer,
+		logger:          loggerOrDefault(options.Logger),
 		customHeaders:   options.CustomHeaders,
 		CookieJar:       options.CookieJar,
-		coloredOutput:   options.ColoredOutput,
-		colorizer:       options.Colorizer,
-		decolorizer:     options.Decolorizer,
+		rateLimiter:     options.RateLimiter,
+		hostErrors:      options.HostErrors,
+		rawOptions:      &rawhttp.Options{Timeout: time.Duration(options.Timeout) * time.Second, DialContext: dialer.DialContext},
+		proxyURL:        options.ProxyURL,
+	}
+
+	// Pipelined requests write to the connections directly, they are sent one by one through an http proxy
+	if options.BulkHTTPRequest.Pipeline && options.ProxyURL != "" {
+		executer.logger.Warningf("Sending the pipelined requests of %s one by one through the http proxy\n", options.Template.ID)
 	}
 
 	return executer, nil
 }
 
-// ExecuteHTTP executes the HTTP request on a URL
-func (e *HTTPExecuter) ExecuteHTTP(ctx context.Context, p progress.IProgress, reqURL string) (result Result) {
+// ExecuteHTTP executes the HTTP request on a URL. The values are variables
+// extracted by previous requests of the template, if any.
+func (e *HTTPExecuter) ExecuteHTTP(ctx context.Context, p progress.IProgress, reqURL string, values map[string]interface{}) (result Result) {
 	result.Matches = make(map[string]interface{})
 	result.Extractions = make(map[string]interface{})
-	dynamicvalues := make(map[string]interface{})
+	dynamicvalues := generators.CopyMap(values)
+
+	remaining := e.bulkHTTPRequest.GetRequestCount()
+	generator := e.bulkHTTPRequest.CreateGenerator(reqURL)
 
-	// verify if the URL is already being processed
-	if e.bulkHTTPRequest.HasGenerator(reqURL) {
-		return
+	defer e.bulkHTTPRequest.DeleteGenerator(generator)
+
+	host := hosterrors.Key(reqURL)
+
+	// Races write to the connections directly and can't go through an http proxy
+	if e.bulkHTTPRequest.Race {
+		if e.proxyURL != "" {
+			result.Error = errors.New("race requests can't be sent through an http proxy")
+
+			p.Drop(remaining)
+
+			return result
+		}
+
+		e.executeRace(ctx, p, reqURL, host, generator, dynamicvalues, &result)
+
+		return result
 	}
 
-	remaining := e.bulkHTTPRequest.GetRequestCount()
-	e.bulkHTTPRequest.CreateGenerator(reqURL)
+	// Pipelined requests are sent as regular requests through an http proxy
+	if e.bulkHTTPRequest.Pipeline && e.proxyURL == "" {
+		e.executePipeline(ctx, p, reqURL, host, generator, dynamicvalues, &result)
+
+		return result
+	}
+
+	for e.bulkHTTPRequest.Next(generator) && !result.Done {
+		// Skip the remaining requests if the host stopped responding
+		if e.hostErrors.IsDead(host) {
+			p.Drop(remaining)
+
+			return
+		}
 
-	for e.bulkHTTPRequest.Next(reqURL) && !result.Done {
-		httpRequest, err := e.bulkHTTPRequest.MakeHTTPRequest(ctx, reqURL, dynamicvalues, e.bulkHTTPRequest.Current(reqURL))
+		httpRequest, err := e.bulkHTTPRequest.MakeHTTPRequest(ctx, reqURL, generator, dynamicvalues, e.bulkHTTPRequest.Current(generator))
 		if err != nil {
 			result.Error = errors.Wrap(err, "could not build http request")
 
@@ -148,8 +178,14 @@ func (e *HTTPExecuter) ExecuteHTTP(ctx context.Context, p progress.IProgress, re
 			return
 		}
 
-		err = e.handleHTTP(reqURL, httpRequest, dynamicvalues, &result)
+		if httpRequest.Unsafe != nil {
+			err = e.handleUnsafeHTTP(ctx, reqURL, host, httpRequest, dynamicvalues, &result)
+		} else {
+			err = e.handleHTTP(reqURL, host, httpRequest, dynamicvalues, &result)
+		}
+
 		if err != nil {
+			e.hostErrors.MarkFailed(host, err)
 			result.Error = errors.Wrap(err, "could not handle http request")
 
 			p.Drop(remaining)
@@ -157,17 +193,18 @@ func (e *HTTPExecuter) ExecuteHTTP(ctx context.Context, p progress.IProgress, re
 			return
 		}
 
-		e.bulkHTTPRequest.Increment(reqURL)
+		e.hostErrors.MarkSuccess(host)
+		e.bulkHTTPRequest.Increment(generator)
 		p.Update()
 		remaining--
 	}
 
-	gologger.Verbosef("Sent HTTP request to %s\n", "http-request", reqURL)
+	e.logger.Verbosef("Sent HTTP request to %s\n", "http-request", reqURL)
 
 	return result
 }
 
-func (e *HTTPExecuter) handleHTTP(reqURL string, request *requests.HTTPRequest, dynamicvalues map[string]interface{}, result *Result) error {
+func (e *HTTPExecuter) handleHTTP(reqURL, host string, request *requests.HTTPRequest, dynamicvalues map[string]interface{}, result *Result) error {
 	e.setCustomHeaders(request)
 	req := request.Request
 
@@ -177,10 +214,12 @@ func (e *HTTPExecuter) handleHTTP(reqURL string, request *requests.HTTPRequest,
 			return errors.Wrap(err, "could not make http request")
 		}
 
-		gologger.Infof("Dumped HTTP request for %s (%s)\n\n", reqURL, e.template.ID)
+		e.logger.Infof("Dumped HTTP request for %s (%s)\n\n", reqURL, e.template.ID)
 		fmt.Fprintf(os.Stderr, "%s", string(dumpedRequest))
 	}
 
+	e.rateLimiter.Take(host)
+
 	resp, err := e.httpClient.Do(req)
 
 	if err != nil {
@@ -197,7 +236,7 @@ func (e *HTTPExecuter) handleHTTP(reqURL string, request *requests.HTTPRequest,
 			return errors.Wrap(dumpErr, "could not dump http response")
 		}
 
-		gologger.Infof("Dumped HTTP response for %s (%s)\n\n", reqURL, e.template.ID)
+		e.logger.Infof("Dumped HTTP response for %s (%s)\n\n", reqURL, e.template.ID)
 		fmt.Fprintf(os.Stderr, "%s\n", string(dumpedResponse))
 	}
 
@@ -216,9 +255,45 @@ func (e *HTTPExecuter) handleHTTP(reqURL string, request *requests.HTTPRequest,
 
 	resp.Body.Close()
 
+	return e.matchResponse(reqURL, request, resp, data, dynamicvalues, result)
+}
+
+// handleUnsafeHTTP writes the bytes of an unsafe request on a new connection and reads the response leniently
+func (e *HTTPExecuter) handleUnsafeHTTP(ctx context.Context, reqURL, host string, request *requests.HTTPRequest, dynamicvalues map[string]interface{}, result *Result) error {
+	if e.proxyURL != "" {
+		return errors.New("unsafe requests can't be sent through an http proxy")
+	}
+
+	rawRequest, err := e.rawRequest(request)
+	if err != nil {
+		return errors.Wrap(err, "could not make http request")
+	}
+
+	if e.debug {
+		e.logger.Infof("Dumped HTTP request for %s (%s)\n\n", reqURL, e.template.ID)
+		fmt.Fprintf(os.Stderr, "%s", string(rawRequest.Data))
+	}
+
+	e.rateLimiter.Take(host)
+
+	resp, err := rawhttp.Do(ctx, e.rawOptions, reqURL, rawRequest)
+	if err != nil {
+		return errors.Wrap(err, "Could not do request")
+	}
+
+	if e.debug {
+		e.logger.Infof("Dumped HTTP response for %s (%s)\n\n", reqURL, e.template.ID)
+		fmt.Fprintf(os.Stderr, "%s %s\n%s\n%s\n", resp.Proto, resp.Status, headersToString(resp.Header), string(resp.Data))
+	}
+
+	return e.matchResponse(reqURL, request, resp.Response, resp.Data, dynamicvalues, result)
+}
+
+// matchResponse runs the matchers and extractors on the response to a request and writes the results
+func (e *HTTPExecuter) matchResponse(reqURL string, request *requests.HTTPRequest, resp *http.Response, data []byte, dynamicvalues map[string]interface{}, result *Result) error {
 	// net/http doesn't automatically decompress the response body if an encoding has been specified by the user in the request
 	// so in case we have to manually do it
-	data, err = requests.HandleDecompression(req, data)
+	data, err := requests.HandleDecompression(request.Request, data)
 	if err != nil {
 		return errors.Wrap(err, "could not decompress http body")
 	}
@@ -243,7 +318,7 @@ func (e *HTTPExecuter) handleHTTP(reqURL string, request *requests.HTTPRequest,
 				result.Matches[matcher.Name] = nil
 				// probably redundant but ensures we snapshot current payload values when matchers are valid
 				result.Meta = request.Meta
-				e.writeOutputHTTP(request, resp, body, matcher, nil)
+				e.writeOutputHTTP(reqURL, request, resp, body, matcher, nil)
 				result.GotResults = true
 			}
 		}
@@ -251,10 +326,12 @@ func (e *HTTPExecuter) handleHTTP(reqURL string, request *requests.HTTPRequest,
 
 	// All matchers have successfully completed so now start with the
 	// next task which is extraction of input from matchers.
-	var extractorResults, outputExtractorResults []string
+	var outputExtractorResults []string
 
 	for _, extractor := range e.bulkHTTPRequest.Extractors {
-		for match := range extractor.Extract(resp, body, headers) {
+		var extractorResults []string
+
+		for match := range extractor.Extract(resp, body, headers, dynamicvalues) {
 			if _, ok := dynamicvalues[extractor.Name]; !ok {
 				dynamicvalues[extractor.Name] = match
 			}
@@ -267,13 +344,17 @@ func (e *HTTPExecuter) handleHTTP(reqURL string, request *requests.HTTPRequest,
 		}
 		// probably redundant but ensures we snapshot current payload values when extractors are valid
 		result.Meta = request.Meta
-		result.Extractions[extractor.Name] = extractorResults
+
+		// keep the values extracted by a previous request of the block if this one has none
+		if _, ok := result.Extractions[extractor.Name]; !ok || len(extractorResults) > 0 {
+			result.Extractions[extractor.Name] = extractorResults
+		}
 	}
 
 	// Write a final string of output if matcher type is
 	// AND or if we have extractors for the mechanism too.
 	if len(outputExtractorResults) > 0 || matcherCondition == matchers.ANDCondition {
-		e.writeOutputHTTP(request, resp, body, nil, outputExtractorResults)
+		e.writeOutputHTTP(reqURL, request, resp, body, nil, outputExtractorResults)
 
 		result.GotResults = true
 	}
@@ -281,67 +362,7 @@ func (e *HTTPExecuter) handleHTTP(reqURL string, request *requests.HTTPRequest,
 	return nil
 }
 
-// Close closes the http executer for a template.
-func (e *HTTPExecuter) Close() {
-	e.outputMutex.Lock()
-	defer e.outputMutex.Unlock()
-	e.writer.Flush()
-}
-
 // makeHTTPClient creates a http client
-func makeHTTPClient(proxyURL *url.URL, options *HTTPOptions) *retryablehttp.Client {
-	retryablehttpOptions := retryablehttp.DefaultOptionsSpraying
-	retryablehttpOptions.RetryWaitMax = 10 * time.Second
-	retryablehttpOptions.RetryMax = options.Retries
-	followRedirects := options.BulkHTTPRequest.Redirects
-	maxRedirects := options.BulkHTTPRequest.MaxRedirects
-
-	transport := &http.Transport{
-		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
-		MaxIdleConnsPerHost: -1,
-		TLSClientConfig: &tls.Config{
-			Renegotiation:      tls.RenegotiateOnceAsClient,
-			InsecureSkipVerify: true,
-		},
-		DisableKeepAlives: true,
-	}
-
-	// Attempts to overwrite the dial function with the socks proxied version
-	if options.ProxySocksURL != "" {
-		var proxyAuth *proxy.Auth
-
-		socksURL, err := url.Parse(options.ProxySocksURL)
-
-		if err == nil {
-			proxyAuth = &proxy.Auth{}
-			proxyAuth.User = socksURL.User.Username()
-			proxyAuth.Password, _ = socksURL.User.Password()
-		}
-
-		dialer, err := proxy.SOCKS5("tcp", fmt.Sprintf("%s:%s", socksURL.Hostname(), socksURL.Port()), proxyAuth, proxy.Direct)
-		dc := dialer.(interface {
-			DialContext(ctx context.Context, network, addr string) (net.Conn, error)
-		})
-
-		if err == nil {
-			transport.DialContext = dc.DialContext
-		}
-	}
-
-	if proxyURL != nil {
-		transport.Proxy = http.ProxyURL(proxyURL)
-	}
-
-	return retryablehttp.NewWithHTTPClient(&http.Client{
-		Transport:     transport,
-		Timeout:       time.Duration(options.Timeout) * time.Second,
-		CheckRedirect: makeCheckRedirectFunc(followRedirects, maxRedirects),
-	}, retryablehttpOptions)
-}
-
 type checkRedirectFunc func(_ *http.Request, requests []*http.Request) error
 
 func makeCheckRedirectFunc(followRedirects bool, maxRedirects int) checkRedirectFunc {
@@ -367,7 +388,12 @@ func makeCheckRedirectFunc(followRedirects bool, maxRedirects int) checkRedirect
 }
 
 func (e *HTTPExecuter) setCustomHeaders(r *requests.HTTPRequest) {
-	for _, customHeader := range e.customHeaders {
+	applyCustomHeaders(r.Request.Header, e.customHeaders)
+}
+
+// applyCustomHeaders sets the global custom headers on a request header
+func applyCustomHeaders(header http.Header, customHeaders requests.CustomHeaders) {
+	for _, customHeader := range customHeaders {
 		// This should be pre-computed somewhere and done only once
 		tokens := strings.Split(customHeader, ":")
 		// if it's an invalid header skip it
@@ -378,7 +404,7 @@ func (e *HTTPExecuter) setCustomHeaders(r *requests.HTTPRequest) {
 		headerName, headerValue := tokens[0], strings.Join(tokens[1:], "")
 		headerName = strings.TrimSpace(headerName)
 		headerValue = strings.TrimSpace(headerValue)
-		r.Request.Header[headerName] = []string{headerValue}
+		header[headerName] = []string{headerValue}
 	}
 }
 
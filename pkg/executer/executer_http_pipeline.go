@@ -0,0 +1,131 @@
+package executer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/rawhttp"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"github.com/projectdiscovery/retryablehttp-go"
+)
+
+// executePipeline builds all the requests of the block for a URL and sends them
+// pipelined over as few connections as possible, then matches the responses in order.
+func (e *HTTPExecuter) executePipeline(ctx context.Context, p progress.IProgress, reqURL, host, generator string, dynamicvalues map[string]interface{}, result *Result) {
+	remaining := e.bulkHTTPRequest.GetRequestCount()
+
+	if e.hostErrors.IsDead(host) {
+		p.Drop(remaining)
+
+		return
+	}
+
+	var (
+		compiledRequests []*requests.HTTPRequest
+		rawRequests      []*rawhttp.Request
+	)
+
+	for e.bulkHTTPRequest.Next(generator) {
+		httpRequest, err := e.bulkHTTPRequest.MakeHTTPRequest(ctx, reqURL, generator, dynamicvalues, e.bulkHTTPRequest.Current(generator))
+		if err != nil {
+			result.Error = errors.Wrap(err, "could not build http request")
+
+			p.Drop(remaining)
+
+			return
+		}
+
+		rawRequest, err := e.rawRequest(httpRequest)
+		if err != nil {
+			result.Error = errors.Wrap(err, "could not dump http request")
+
+			p.Drop(remaining)
+
+			return
+		}
+
+		if e.debug {
+			e.logger.Infof("Dumped HTTP request for %s (%s)\n\n", reqURL, e.template.ID)
+			fmt.Fprintf(os.Stderr, "%s", string(rawRequest.Data))
+		}
+
+		compiledRequests = append(compiledRequests, httpRequest)
+		rawRequests = append(rawRequests, rawRequest)
+
+		e.bulkHTTPRequest.Increment(generator)
+	}
+
+	for range rawRequests {
+		e.rateLimiter.Take(host)
+	}
+
+	responses, err := rawhttp.Pipeline(ctx, e.rawOptions, reqURL, rawRequests, e.bulkHTTPRequest.GetPipelineDepth())
+
+	for i, resp := range responses {
+		if e.debug {
+			e.logger.Infof("Dumped HTTP response for %s (%s)\n\n", reqURL, e.template.ID)
+			fmt.Fprintf(os.Stderr, "%s %s\n%s\n%s\n", resp.Proto, resp.Status, headersToString(resp.Header), string(resp.Data))
+		}
+
+		resp.Body = ioutil.NopCloser(bytes.NewReader(resp.Data))
+
+		if matchErr := e.matchResponse(reqURL, compiledRequests[i], resp.Response, resp.Data, dynamicvalues, result); matchErr != nil {
+			e.logger.Warningf("Could not match http response: %s\n", matchErr)
+		}
+
+		p.Update()
+		remaining--
+	}
+
+	if err != nil {
+		e.hostErrors.MarkFailed(host, err)
+		result.Error = errors.Wrap(err, "could not pipeline http requests")
+
+		p.Drop(remaining)
+
+		return
+	}
+
+	e.hostErrors.MarkSuccess(host)
+
+	e.logger.Verbosef("Sent %d pipelined HTTP requests to %s\n", "http-request", len(rawRequests), reqURL)
+}
+
+// rawRequest returns the raw request to write on the connection for a request
+func (e *HTTPExecuter) rawRequest(request *requests.HTTPRequest) (*rawhttp.Request, error) {
+	if request.Unsafe != nil {
+		return &rawhttp.Request{Method: request.Unsafe.Method, Data: request.Unsafe.Data, Lenient: true}, nil
+	}
+
+	e.setCustomHeaders(request)
+
+	data, err := dumpRawRequest(request.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rawhttp.Request{Method: request.Request.Method, Data: data}, nil
+}
+
+// dumpRawRequest returns the bytes of a request as sent on the wire
+func dumpRawRequest(req *retryablehttp.Request) ([]byte, error) {
+	body, err := req.BodyBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	req.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	req.Request.ContentLength = int64(len(body))
+
+	buffer := &bytes.Buffer{}
+	if err := req.Request.Write(buffer); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,207 @@
+package executer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/rawhttp"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+)
+
+// raceResponse is a response received in race mode, ready for matching
+type raceResponse struct {
+	*rawhttp.Response
+	body    string
+	headers string
+}
+
+// executeRace sends each request of the block for a URL over several connections
+// at the same time, then matches the whole set of responses of each request.
+func (e *HTTPExecuter) executeRace(ctx context.Context, p progress.IProgress, reqURL, host, generator string, dynamicvalues map[string]interface{}, result *Result) {
+	remaining := e.bulkHTTPRequest.GetRequestCount()
+	count := e.bulkHTTPRequest.GetRaceCount()
+
+	for e.bulkHTTPRequest.Next(generator) && !result.Done {
+		if e.hostErrors.IsDead(host) {
+			p.Drop(remaining)
+
+			return
+		}
+
+		httpRequest, err := e.bulkHTTPRequest.MakeHTTPRequest(ctx, reqURL, generator, dynamicvalues, e.bulkHTTPRequest.Current(generator))
+		if err != nil {
+			result.Error = errors.Wrap(err, "could not build http request")
+
+			p.Drop(remaining)
+
+			return
+		}
+
+		rawRequest, err := e.rawRequest(httpRequest)
+		if err != nil {
+			result.Error = errors.Wrap(err, "could not dump http request")
+
+			p.Drop(remaining)
+
+			return
+		}
+
+		if e.debug {
+			e.logger.Infof("Dumped HTTP request for %s (%s), sent %d times\n\n", reqURL, e.template.ID, count)
+			fmt.Fprintf(os.Stderr, "%s", string(rawRequest.Data))
+		}
+
+		for i := 0; i < count; i++ {
+			e.rateLimiter.Take(host)
+		}
+
+		responses, err := rawhttp.Race(ctx, e.rawOptions, reqURL, rawRequest, count)
+		if err != nil {
+			e.hostErrors.MarkFailed(host, err)
+			result.Error = errors.Wrap(err, "could not race http requests")
+
+			p.Drop(remaining)
+
+			return
+		}
+
+		e.hostErrors.MarkSuccess(host)
+
+		if err := e.matchRaceResponses(reqURL, httpRequest, responses, dynamicvalues, result); err != nil {
+			result.Error = errors.Wrap(err, "could not match http responses")
+
+			p.Drop(remaining)
+
+			return
+		}
+
+		e.bulkHTTPRequest.Increment(generator)
+		p.Update()
+		remaining--
+	}
+
+	e.logger.Verbosef("Sent raced HTTP requests to %s\n", "http-request", reqURL)
+}
+
+// matchRaceResponses runs the matchers and extractors on the set of responses to a request sent in
+// race mode. A matcher matches the set if it matches at least its minimum number of responses.
+func (e *HTTPExecuter) matchRaceResponses(reqURL string, request *requests.HTTPRequest, responses []*rawhttp.Response, dynamicvalues map[string]interface{}, result *Result) error {
+	raceResponses := make([]*raceResponse, 0, len(responses))
+
+	for _, resp := range responses {
+		data, err := requests.HandleDecompression(request.Request, resp.Data)
+		if err != nil {
+			return errors.Wrap(err, "could not decompress http body")
+		}
+
+		resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+		if e.debug {
+			e.logger.Infof("Dumped HTTP response for %s (%s)\n\n", reqURL, e.template.ID)
+			fmt.Fprintf(os.Stderr, "%s %s\n%s\n%s\n", resp.Proto, resp.Status, headersToString(resp.Header), string(data))
+		}
+
+		raceResponses = append(raceResponses, &raceResponse{Response: resp, body: string(data), headers: headersToString(resp.Header)})
+	}
+
+	matcherCondition := e.bulkHTTPRequest.GetMatchersCondition()
+
+	for _, matcher := range e.bulkHTTPRequest.Matchers {
+		// Check if the matcher matched enough responses
+		matched := matchRace(matcher, raceResponses)
+		if matched == nil {
+			// If the condition is AND we haven't matched, try next request.
+			if matcherCondition == matchers.ANDCondition {
+				return nil
+			}
+		} else {
+			// If the matcher has matched, and its an OR
+			// write the first output then move to next matcher.
+			if matcherCondition == matchers.ORCondition {
+				result.Matches[matcher.Name] = nil
+				result.Meta = request.Meta
+				e.writeOutputHTTP(reqURL, request, matched.Response.Response, matched.body, matcher, nil)
+				result.GotResults = true
+			}
+		}
+	}
+
+	// All matchers have successfully completed so now start with the
+	// next task which is extraction of input from matchers.
+	var outputExtractorResults []string
+
+	for _, extractor := range e.bulkHTTPRequest.Extractors {
+		var extractorResults []string
+
+		seen := make(map[string]struct{})
+
+		for _, resp := range raceResponses {
+			for match := range extractor.Extract(resp.Response.Response, resp.body, resp.headers, dynamicvalues) {
+				if _, ok := seen[match]; ok {
+					continue
+				}
+
+				seen[match] = struct{}{}
+
+				if _, ok := dynamicvalues[extractor.Name]; !ok {
+					dynamicvalues[extractor.Name] = match
+				}
+
+				extractorResults = append(extractorResults, match)
+
+				if !extractor.Internal {
+					outputExtractorResults = append(outputExtractorResults, match)
+				}
+			}
+		}
+
+		result.Meta = request.Meta
+
+		if _, ok := result.Extractions[extractor.Name]; !ok || len(extractorResults) > 0 {
+			result.Extractions[extractor.Name] = extractorResults
+		}
+	}
+
+	// Write a final string of output if matcher type is
+	// AND or if we have extractors for the mechanism too.
+	if len(outputExtractorResults) > 0 || matcherCondition == matchers.ANDCondition {
+		first := raceResponses[0]
+		e.writeOutputHTTP(reqURL, request, first.Response.Response, first.body, nil, outputExtractorResults)
+
+		result.GotResults = true
+	}
+
+	return nil
+}
+
+// matchRace returns the first response matched by the matcher if it matched
+// at least its minimum number of responses, or nil otherwise.
+func matchRace(matcher *matchers.Matcher, responses []*raceResponse) *raceResponse {
+	var first *raceResponse
+
+	count := 0
+
+	for _, resp := range responses {
+		if !matcher.Match(resp.Response.Response, resp.body, resp.headers) {
+			continue
+		}
+
+		if first == nil {
+			first = resp
+		}
+
+		count++
+	}
+
+	if count < matcher.GetMinMatches() {
+		return nil
+	}
+
+	return first
+}
@@ -0,0 +1,240 @@
+package executer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// NetworkExecuter is a client for performing raw network requests
+// for a template.
+type NetworkExecuter struct {
+	debug          bool
+	jsonRequest    bool
+	Results        bool
+	retries        int
+	timeout        time.Duration
+	dialer         contextDialer
+	template       *templates.Template
+	networkRequest *requests.NetworkRequest
+	writer         OutputWriter
+	logger         Logger
+}
+
+// NetworkOptions contains configuration options for the network executer.
+type NetworkOptions struct {
+	Debug          bool
+	JSONRequests   bool
+	Timeout        int
+	Retries        int
+	ProxySocksURL  string
+	Template       *templates.Template
+	NetworkRequest *requests.NetworkRequest
+	Writer         OutputWriter
+	Logger         Logger
+}
+
+// NewNetworkExecuter creates a new network executer from a template
+// and a network request query.
+func NewNetworkExecuter(options *NetworkOptions) (*NetworkExecuter, error) {
+	timeout := time.Duration(options.Timeout) * time.Second
+
+	dialer, err := newDialer(timeout, options.ProxySocksURL)
+	if err != nil {
+		return nil, err
+	}
+
+	executer := &NetworkExecuter{
+		debug:          options.Debug,
+		jsonRequest:    options.JSONRequests,
+		retries:        options.Retries,
+		timeout:        timeout,
+		dialer:         dialer,
+		template:       options.Template,
+		networkRequest: options.NetworkRequest,
+		writer:         options.Writer,
+		logger:         loggerOrDefault(options.Logger),
+	}
+
+	return executer, nil
+}
+
+// ExecuteNetwork executes the network request on a URL. The values are variables
+// extracted by previous requests of the template, if any.
+func (e *NetworkExecuter) ExecuteNetwork(ctx context.Context, p progress.IProgress, reqURL string, values map[string]interface{}) (result Result) {
+	result.Matches = make(map[string]interface{})
+	result.Extractions = make(map[string]interface{})
+
+	// Parse the URL and return host if URL.
+	var host string
+	if isURL(reqURL) {
+		host = extractHost(reqURL)
+	} else {
+		host = reqURL
+	}
+
+	compiledRequests, err := e.networkRequest.MakeNetworkRequests(host, values)
+	if err != nil {
+		result.Error = errors.Wrap(err, "could not make network request")
+
+		p.Drop(e.networkRequest.GetRequestCount())
+
+		return
+	}
+
+	for _, compiledRequest := range compiledRequests {
+		err := e.handleNetwork(ctx, reqURL, compiledRequest, values, &result)
+		if err != nil {
+			result.Error = errors.Wrap(err, "could not handle network request")
+
+			p.Drop(1)
+
+			continue
+		}
+
+		p.Update()
+	}
+
+	e.logger.Verbosef("Sent Network request to %s\n", "network-request", reqURL)
+
+	return result
+}
+
+func (e *NetworkExecuter) handleNetwork(ctx context.Context, reqURL string, request *requests.CompiledNetworkRequest, values map[string]interface{}, result *Result) error {
+	conn, err := e.dial(ctx, request)
+	if err != nil {
+		return errors.Wrap(err, "could not connect to server")
+	}
+	defer conn.Close()
+
+	err = conn.SetDeadline(time.Now().Add(e.timeout))
+	if err != nil {
+		return errors.Wrap(err, "could not set connection deadline")
+	}
+
+	sent := &strings.Builder{}
+	received := &strings.Builder{}
+
+	for _, input := range request.Inputs {
+		if _, err := conn.Write(input.Data); err != nil {
+			return errors.Wrap(err, "could not write request to server")
+		}
+
+		sent.Write(input.Data)
+
+		if input.Read > 0 {
+			buffer := make([]byte, input.Read)
+
+			n, err := conn.Read(buffer)
+			if err != nil {
+				return errors.Wrap(err, "could not read response from server")
+			}
+
+			received.Write(buffer[:n])
+		}
+	}
+
+	if e.debug {
+		e.logger.Infof("Dumped Network request for %s (%s)\n\n", request.Address, e.template.ID)
+		fmt.Fprintf(os.Stderr, "%s\n", sent.String())
+	}
+
+	// Read the final response from the server. Read errors are not fatal
+	// here as servers are free to close the connection or to not answer at all.
+	buffer := make([]byte, request.ReadSize)
+	n, _ := conn.Read(buffer)
+	received.Write(buffer[:n])
+
+	data := received.String()
+
+	if e.debug {
+		e.logger.Infof("Dumped Network response for %s (%s)\n\n", request.Address, e.template.ID)
+		fmt.Fprintf(os.Stderr, "%s\n", data)
+	}
+
+	matcherCondition := e.networkRequest.GetMatchersCondition()
+
+	for _, matcher := range e.networkRequest.Matchers {
+		// Check if the matcher matched
+		if !matcher.MatchNetwork(data) {
+			// If the condition is AND we haven't matched, return.
+			if matcherCondition == matchers.ANDCondition {
+				return nil
+			}
+		} else {
+			// If the matcher has matched, and its an OR
+			// write the first output then move to next matcher.
+			if matcherCondition == matchers.ORCondition && len(e.networkRequest.Extractors) == 0 {
+				result.Matches[matcher.Name] = nil
+				e.writeOutputNetwork(reqURL, request.Address, sent.String(), data, matcher, nil)
+				result.GotResults = true
+			}
+		}
+	}
+
+	// All matchers have successfully completed so now start with the
+	// next task which is extraction of input from matchers.
+	var extractorResults []string
+
+	// The values extracted by the previous extractors are available to dsl extractors
+	extractedValues := generators.CopyMap(values)
+
+	for _, extractor := range e.networkRequest.Extractors {
+		var extracted []string
+
+		for match := range extractor.ExtractNetwork(data, extractedValues) {
+			if _, ok := extractedValues[extractor.Name]; !ok {
+				extractedValues[extractor.Name] = match
+			}
+
+			extracted = append(extracted, match)
+
+			if !extractor.Internal {
+				extractorResults = append(extractorResults, match)
+			}
+		}
+
+		// keep the values extracted from a previous address if this one has none
+		if _, ok := result.Extractions[extractor.Name]; !ok || len(extracted) > 0 {
+			result.Extractions[extractor.Name] = extracted
+		}
+	}
+
+	// Write a final string of output if matcher type is
+	// AND or if we have extractors for the mechanism too.
+	if len(e.networkRequest.Extractors) > 0 || matcherCondition == matchers.ANDCondition {
+		e.writeOutputNetwork(reqURL, request.Address, sent.String(), data, nil, extractorResults)
+
+		result.GotResults = true
+	}
+
+	return nil
+}
+
+// dial connects to the address of the request, retrying on failures
+func (e *NetworkExecuter) dial(ctx context.Context, request *requests.CompiledNetworkRequest) (conn net.Conn, err error) {
+	for i := 0; i <= e.retries; i++ {
+		// socks proxies only support tcp connections
+		if request.Protocol == "udp" {
+			conn, err = (&net.Dialer{Timeout: e.timeout}).DialContext(ctx, request.Protocol, request.Address)
+		} else {
+			conn, err = e.dialer.DialContext(ctx, request.Protocol, request.Address)
+		}
+
+		if err == nil {
+			return conn, nil
+		}
+	}
+
+	return nil, err
+}
@@ -0,0 +1,203 @@
+package executer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// SSLExecuter is a client for performing tls handshakes
+// for a template.
+type SSLExecuter struct {
+	debug       bool
+	jsonRequest bool
+	Results     bool
+	retries     int
+	timeout     time.Duration
+	dialer      contextDialer
+	template    *templates.Template
+	sslRequest  *requests.SSLRequest
+	writer      OutputWriter
+	logger      Logger
+}
+
+// SSLOptions contains configuration options for the ssl executer.
+type SSLOptions struct {
+	Debug         bool
+	JSONRequests  bool
+	Timeout       int
+	Retries       int
+	ProxySocksURL string
+	Template      *templates.Template
+	SSLRequest    *requests.SSLRequest
+	Writer        OutputWriter
+	Logger        Logger
+}
+
+// NewSSLExecuter creates a new ssl executer from a template
+// and a ssl request query.
+func NewSSLExecuter(options *SSLOptions) (*SSLExecuter, error) {
+	timeout := time.Duration(options.Timeout) * time.Second
+
+	dialer, err := newDialer(timeout, options.ProxySocksURL)
+	if err != nil {
+		return nil, err
+	}
+
+	executer := &SSLExecuter{
+		debug:       options.Debug,
+		jsonRequest: options.JSONRequests,
+		retries:     options.Retries,
+		timeout:     timeout,
+		dialer:      dialer,
+		template:    options.Template,
+		sslRequest:  options.SSLRequest,
+		writer:      options.Writer,
+		logger:      loggerOrDefault(options.Logger),
+	}
+
+	return executer, nil
+}
+
+// ExecuteSSL executes the ssl request on a URL. The values are variables
+// extracted by previous requests of the template, if any.
+func (e *SSLExecuter) ExecuteSSL(ctx context.Context, p progress.IProgress, reqURL string, values map[string]interface{}) (result Result) {
+	result.Matches = make(map[string]interface{})
+	result.Extractions = make(map[string]interface{})
+
+	// Parse the URL and return host if URL.
+	var host string
+	if isURL(reqURL) {
+		host = extractHost(reqURL)
+	} else {
+		host = reqURL
+	}
+
+	compiledRequest, err := e.sslRequest.MakeSSLRequest(host, values)
+	if err != nil {
+		result.Error = errors.Wrap(err, "could not make ssl request")
+
+		p.Drop(1)
+
+		return
+	}
+
+	if e.debug {
+		e.logger.Infof("Dumped SSL request for %s (%s)\n\n", reqURL, e.template.ID)
+		fmt.Fprintf(os.Stderr, "%s (server name: %s)\n", compiledRequest.Address, compiledRequest.Config.ServerName)
+	}
+
+	data, err := e.handshake(ctx, compiledRequest)
+	if err != nil {
+		result.Error = errors.Wrap(err, "could not connect to server")
+
+		p.Drop(1)
+
+		return
+	}
+
+	p.Update()
+
+	e.logger.Verbosef("Sent SSL request to %s\n", "ssl-request", reqURL)
+
+	raw, _ := data["raw"].(string)
+
+	if e.debug {
+		e.logger.Infof("Dumped SSL response for %s (%s)\n\n", reqURL, e.template.ID)
+		fmt.Fprintf(os.Stderr, "%s\n", raw)
+	}
+
+	matcherCondition := e.sslRequest.GetMatchersCondition()
+
+	for _, matcher := range e.sslRequest.Matchers {
+		// Check if the matcher matched
+		if !matcher.MatchSSL(data) {
+			// If the condition is AND we haven't matched, return.
+			if matcherCondition == matchers.ANDCondition {
+				return
+			}
+		} else {
+			// If the matcher has matched, and its an OR
+			// write the first output then move to next matcher.
+			if matcherCondition == matchers.ORCondition && len(e.sslRequest.Extractors) == 0 {
+				result.Matches[matcher.Name] = nil
+				e.writeOutputSSL(reqURL, compiledRequest.Address, raw, matcher, nil)
+				result.GotResults = true
+			}
+		}
+	}
+
+	// All matchers have successfully completed so now start with the
+	// next task which is extraction of input from matchers.
+	var extractorResults []string
+
+	// The values extracted by the previous extractors are available to dsl extractors
+	extractedValues := generators.CopyMap(values)
+
+	for _, extractor := range e.sslRequest.Extractors {
+		var extracted []string
+
+		for match := range extractor.ExtractSSL(data, extractedValues) {
+			if _, ok := extractedValues[extractor.Name]; !ok {
+				extractedValues[extractor.Name] = match
+			}
+
+			extracted = append(extracted, match)
+
+			if !extractor.Internal {
+				extractorResults = append(extractorResults, match)
+			}
+		}
+
+		result.Extractions[extractor.Name] = extracted
+	}
+
+	// Write a final string of output if matcher type is
+	// AND or if we have extractors for the mechanism too.
+	if len(e.sslRequest.Extractors) > 0 || matcherCondition == matchers.ANDCondition {
+		e.writeOutputSSL(reqURL, compiledRequest.Address, raw, nil, extractorResults)
+
+		result.GotResults = true
+	}
+
+	return result
+}
+
+// handshake connects to the server and performs the tls handshake. A failed
+// handshake is not an error as it is exposed to the matchers, so that
+// unsupported versions and cipher suites can be detected.
+func (e *SSLExecuter) handshake(ctx context.Context, request *requests.CompiledSSLRequest) (map[string]interface{}, error) {
+	var err error
+
+	for i := 0; i <= e.retries; i++ {
+		conn, dialErr := e.dialer.DialContext(ctx, "tcp", request.Address)
+		if dialErr != nil {
+			err = dialErr
+			continue
+		}
+
+		tlsConn := tls.Client(conn, request.Config)
+
+		if err := tlsConn.SetDeadline(time.Now().Add(e.timeout)); err != nil {
+			tlsConn.Close()
+			return nil, err
+		}
+
+		handshakeErr := tlsConn.Handshake()
+		state := tlsConn.ConnectionState()
+		tlsConn.Close()
+
+		return sslToMap(request.Address, request.Config.ServerName, &state, handshakeErr), nil
+	}
+
+	return nil, err
+}
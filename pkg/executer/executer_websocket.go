@@ -0,0 +1,269 @@
+package executer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// WebSocketExecuter is a client for performing websocket requests
+// for a template.
+type WebSocketExecuter struct {
+	debug            bool
+	jsonRequest      bool
+	Results          bool
+	retries          int
+	timeout          time.Duration
+	dialer           *websocket.Dialer
+	template         *templates.Template
+	websocketRequest *requests.WebSocketRequest
+	writer           OutputWriter
+	logger           Logger
+	customHeaders    requests.CustomHeaders
+}
+
+// WebSocketOptions contains configuration options for the websocket executer.
+type WebSocketOptions struct {
+	Debug            bool
+	JSONRequests     bool
+	Timeout          int
+	Retries          int
+	ProxyURL         string
+	ProxySocksURL    string
+	CustomHeaders    requests.CustomHeaders
+	Template         *templates.Template
+	WebSocketRequest *requests.WebSocketRequest
+	Writer           OutputWriter
+	Logger           Logger
+}
+
+// NewWebSocketExecuter creates a new websocket executer from a template
+// and a websocket request query.
+func NewWebSocketExecuter(options *WebSocketOptions) (*WebSocketExecuter, error) {
+	timeout := time.Duration(options.Timeout) * time.Second
+
+	netDialer, err := newDialer(timeout, options.ProxySocksURL)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &websocket.Dialer{
+		NetDialContext:   netDialer.DialContext,
+		HandshakeTimeout: timeout,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+		},
+	}
+
+	if options.ProxyURL != "" {
+		proxyURL, err := url.Parse(options.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+
+		dialer.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	executer := &WebSocketExecuter{
+		debug:            options.Debug,
+		jsonRequest:      options.JSONRequests,
+		retries:          options.Retries,
+		timeout:          timeout,
+		dialer:           dialer,
+		template:         options.Template,
+		websocketRequest: options.WebSocketRequest,
+		writer:           options.Writer,
+		logger:           loggerOrDefault(options.Logger),
+		customHeaders:    options.CustomHeaders,
+	}
+
+	return executer, nil
+}
+
+// ExecuteWebSocket executes the websocket request on a URL. The values are variables
+// extracted by previous requests of the template, if any.
+func (e *WebSocketExecuter) ExecuteWebSocket(ctx context.Context, p progress.IProgress, reqURL string, values map[string]interface{}) (result Result) {
+	result.Matches = make(map[string]interface{})
+	result.Extractions = make(map[string]interface{})
+
+	compiledRequest, err := e.websocketRequest.MakeWebSocketRequest(reqURL, values)
+	if err != nil {
+		result.Error = errors.Wrap(err, "could not make websocket request")
+
+		p.Drop(1)
+
+		return
+	}
+
+	applyCustomHeaders(compiledRequest.Headers, e.customHeaders)
+
+	err = e.handleWebSocket(ctx, reqURL, compiledRequest, values, &result)
+	if err != nil {
+		result.Error = errors.Wrap(err, "could not handle websocket request")
+
+		p.Drop(1)
+
+		return
+	}
+
+	p.Update()
+
+	e.logger.Verbosef("Sent WebSocket request to %s\n", "websocket-request", reqURL)
+
+	return result
+}
+
+func (e *WebSocketExecuter) handleWebSocket(ctx context.Context, reqURL string, request *requests.CompiledWebSocketRequest, values map[string]interface{}, result *Result) error {
+	if e.debug {
+		e.logger.Infof("Dumped WebSocket request for %s (%s)\n\n", request.URL, e.template.ID)
+		fmt.Fprintf(os.Stderr, "GET %s\n%s\n", request.URL, headersToString(request.Headers))
+	}
+
+	conn, resp, err := e.dial(ctx, request)
+
+	// A rejected handshake still carries a response which can be inspected
+	// by matchers, for example to check for origin validation.
+	if err != nil && resp == nil {
+		return errors.Wrap(err, "could not connect to server")
+	}
+
+	sent := &strings.Builder{}
+	received := &strings.Builder{}
+
+	if conn != nil {
+		defer conn.Close()
+
+		if err := e.exchangeMessages(conn, request, sent, received); err != nil {
+			return err
+		}
+	} else {
+		data, _ := ioutil.ReadAll(resp.Body)
+		received.Write(data)
+	}
+
+	resp.Body.Close()
+
+	// The frames received are exposed to matchers as the body, and the
+	// handshake response as the status code and headers.
+	body := received.String()
+	headers := headersToString(resp.Header)
+
+	if e.debug {
+		e.logger.Infof("Dumped WebSocket response for %s (%s)\n\n", request.URL, e.template.ID)
+		fmt.Fprintf(os.Stderr, "%s\n%s\n%s\n", resp.Status, headers, body)
+	}
+
+	matcherCondition := e.websocketRequest.GetMatchersCondition()
+
+	for _, matcher := range e.websocketRequest.Matchers {
+		// Check if the matcher matched
+		if !matcher.Match(resp, body, headers) {
+			// If the condition is AND we haven't matched, return.
+			if matcherCondition == matchers.ANDCondition {
+				return nil
+			}
+		} else {
+			// If the matcher has matched, and its an OR
+			// write the first output then move to next matcher.
+			if matcherCondition == matchers.ORCondition && len(e.websocketRequest.Extractors) == 0 {
+				result.Matches[matcher.Name] = nil
+				e.writeOutputWebSocket(reqURL, request, resp, sent.String(), body, matcher, nil)
+				result.GotResults = true
+			}
+		}
+	}
+
+	// All matchers have successfully completed so now start with the
+	// next task which is extraction of input from matchers.
+	var extractorResults []string
+
+	// The values extracted by the previous extractors are available to dsl extractors
+	extractedValues := generators.CopyMap(values)
+
+	for _, extractor := range e.websocketRequest.Extractors {
+		var extracted []string
+
+		for match := range extractor.Extract(resp, body, headers, extractedValues) {
+			if _, ok := extractedValues[extractor.Name]; !ok {
+				extractedValues[extractor.Name] = match
+			}
+
+			extracted = append(extracted, match)
+
+			if !extractor.Internal {
+				extractorResults = append(extractorResults, match)
+			}
+		}
+
+		result.Extractions[extractor.Name] = extracted
+	}
+
+	// Write a final string of output if matcher type is
+	// AND or if we have extractors for the mechanism too.
+	if len(extractorResults) > 0 || matcherCondition == matchers.ANDCondition {
+		e.writeOutputWebSocket(reqURL, request, resp, sent.String(), body, nil, extractorResults)
+
+		result.GotResults = true
+	}
+
+	return nil
+}
+
+// dial performs the websocket handshake, retrying on connection failures
+func (e *WebSocketExecuter) dial(ctx context.Context, request *requests.CompiledWebSocketRequest) (conn *websocket.Conn, resp *http.Response, err error) {
+	for i := 0; i <= e.retries; i++ {
+		conn, resp, err = e.dialer.DialContext(ctx, request.URL, request.Headers)
+		if err == nil || resp != nil {
+			return conn, resp, err
+		}
+	}
+
+	return nil, nil, err
+}
+
+// exchangeMessages sends the messages of the request in order and collects the received frames
+// until the expected number of frames is read or no more frames can be read.
+func (e *WebSocketExecuter) exchangeMessages(conn *websocket.Conn, request *requests.CompiledWebSocketRequest, sent, received *strings.Builder) error {
+	if err := conn.SetReadDeadline(time.Now().Add(e.timeout)); err != nil {
+		return err
+	}
+
+	for _, message := range request.Messages {
+		if err := conn.WriteMessage(message.Type, message.Data); err != nil {
+			return errors.Wrap(err, "could not write message to server")
+		}
+
+		sent.Write(message.Data)
+		sent.WriteRune('\n')
+
+		for i := 0; i < message.Read; i++ {
+			// The server closing the connection or sending fewer messages than expected before
+			// the deadline ends the exchange, the messages received so far are still matched.
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				e.logger.Verbosef("Stopped reading WebSocket messages from %s: %s\n", "websocket-request", request.URL, err)
+
+				return nil
+			}
+
+			received.Write(data)
+			received.WriteRune('\n')
+		}
+	}
+
+	return nil
+}
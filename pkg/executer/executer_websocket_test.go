@@ -0,0 +1,154 @@
+package executer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/projectdiscovery/nuclei/v2/internal/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/stretchr/testify/require"
+)
+
+// serveWebSocket starts a server accepting the handshakes without an origin or from its own
+// origin. It answers each message with an echo and a count of the messages received, then
+// waits for the next one.
+func serveWebSocket(t *testing.T) *httptest.Server {
+	var origin string
+
+	upgrader := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool {
+		return r.Header.Get("Origin") == "" || r.Header.Get("Origin") == origin
+	}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for count := 1; ; count++ {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			_ = conn.WriteMessage(websocket.TextMessage, []byte("echo: "+string(data)))
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf("count: %d", count)))
+		}
+	}))
+	origin = server.URL
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// executeWebSocket runs the websocket request of a template on a target
+func executeWebSocket(t *testing.T, data, target string) (Result, *testWriter) {
+	template, err := templates.ParseBytes([]byte(data), "websocket.yaml")
+	require.Nil(t, err, "Could not parse template")
+
+	writer := &testWriter{}
+
+	execute, err := NewRequestExecuter(template, template.GetRequests()[0], &Options{Timeout: 1, Writer: writer, Logger: NoOpLogger{}})
+	require.Nil(t, err, "Could not create executer")
+
+	return execute(context.Background(), &progress.NoOpProgress{}, target, nil), writer
+}
+
+func TestWebSocketExchange(t *testing.T) {
+	server := serveWebSocket(t)
+
+	result, writer := executeWebSocket(t, `id: websocket-exchange
+info:
+  name: websocket exchange
+  author: test
+  severity: info
+websocket:
+  - origin: "{{BaseURL}}"
+    inputs:
+      - data: hello
+        read: 2
+    matchers-condition: and
+    matchers:
+      - type: status
+        status:
+          - 101
+      - type: word
+        condition: and
+        words:
+          - "echo: hello"
+          - "count: 1"
+    extractors:
+      - type: regex
+        name: count
+        regex:
+          - "count: [0-9]+"
+`, server.URL)
+	require.Nil(t, result.Error, "Could not exchange messages")
+	require.True(t, result.GotResults, "Could not match exchange")
+	require.Equal(t, []string{"count: 1"}, result.Extractions["count"], "Could not extract from received messages")
+	require.Len(t, writer.events, 1, "Could not write result event")
+	require.Equal(t, "websocket", writer.events[0].Type, "Could not get type of event")
+	require.Equal(t, []string{"count: 1"}, writer.events[0].ExtractedResults, "Could not write extracted results")
+}
+
+func TestWebSocketForeignOrigin(t *testing.T) {
+	server := serveWebSocket(t)
+
+	result, writer := executeWebSocket(t, `id: websocket-origin
+info:
+  name: websocket origin
+  author: test
+  severity: info
+websocket:
+  - origin: https://attacker.example
+    inputs:
+      - data: hello
+        read: 1
+    matchers:
+      - type: status
+        name: rejected
+        status:
+          - 403
+`, server.URL)
+	require.Nil(t, result.Error, "Could not match rejected handshake")
+	require.True(t, result.GotResults, "Could not match rejected handshake")
+	require.Len(t, writer.events, 1, "Could not write result event")
+	require.Equal(t, "rejected", writer.events[0].MatcherName, "Could not get matcher of event")
+}
+
+func TestWebSocketReadDeadline(t *testing.T) {
+	server := serveWebSocket(t)
+
+	// The server sends two messages only, the third read stops at the deadline
+	// and the messages received before are still matched.
+	start := time.Now()
+
+	result, writer := executeWebSocket(t, `id: websocket-deadline
+info:
+  name: websocket deadline
+  author: test
+  severity: info
+websocket:
+  - inputs:
+      - data: hello
+        read: 3
+    matchers:
+      - type: word
+        name: count
+        words:
+          - "count: 1"
+`, server.URL)
+	elapsed := time.Since(start)
+
+	require.Nil(t, result.Error, "Could not exchange messages")
+	require.True(t, result.GotResults, "Could not match messages received before the deadline")
+	require.Len(t, writer.events, 1, "Could not write result event")
+	require.True(t, elapsed >= time.Second && elapsed < 3*time.Second, "Could not stop reading at the deadline: %s", elapsed)
+}
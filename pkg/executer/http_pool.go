@@ -0,0 +1,148 @@
+package executer
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/retryablehttp-go"
+)
+
+// HTTPClientPoolOptions contains the options for the connections of the pooled clients
+type HTTPClientPoolOptions struct {
+	// KeepAlive enables the reuse of the connections between requests
+	KeepAlive bool
+	// MaxConnsPerHost limits the number of connections to a host, 0 for no limit
+	MaxConnsPerHost int
+}
+
+// HTTPClientPool shares the HTTP clients, and their connections, between
+// the executers with the same settings. It is safe for concurrent use.
+type HTTPClientPool struct {
+	options    HTTPClientPoolOptions
+	transports map[transportKey]*http.Transport
+	clients    map[clientKey]*retryablehttp.Client
+	mutex      sync.Mutex
+}
+
+// transportKey contains the settings of a shared transport
+type transportKey struct {
+	proxyURL      string
+	proxySocksURL string
+}
+
+// clientKey contains the settings of a shared client
+type clientKey struct {
+	transportKey
+	followRedirects bool
+	maxRedirects    int
+	timeout         int
+	retries         int
+}
+
+// NewHTTPClientPool creates a new pool of HTTP clients
+func NewHTTPClientPool(options *HTTPClientPoolOptions) *HTTPClientPool {
+	pool := &HTTPClientPool{
+		transports: make(map[transportKey]*http.Transport),
+		clients:    make(map[clientKey]*retryablehttp.Client),
+	}
+
+	if options != nil {
+		pool.options = *options
+	}
+
+	return pool
+}
+
+// Get returns the client for the options of an HTTP executer. Executers needing
+// a cookie jar get their own client, still sharing the pooled connections.
+func (p *HTTPClientPool) Get(options *HTTPOptions, jar http.CookieJar) (*retryablehttp.Client, error) {
+	key := clientKey{
+		transportKey:    transportKey{proxyURL: options.ProxyURL, proxySocksURL: options.ProxySocksURL},
+		followRedirects: options.BulkHTTPRequest.Redirects,
+		maxRedirects:    options.BulkHTTPRequest.MaxRedirects,
+		timeout:         options.Timeout,
+		retries:         options.Retries,
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if client, ok := p.clients[key]; ok && jar == nil {
+		return client, nil
+	}
+
+	transport, ok := p.transports[key.transportKey]
+	if !ok {
+		var err error
+
+		transport, err = p.newTransport(key.transportKey)
+		if err != nil {
+			return nil, err
+		}
+
+		p.transports[key.transportKey] = transport
+	}
+
+	retryablehttpOptions := retryablehttp.DefaultOptionsSpraying
+	retryablehttpOptions.RetryWaitMax = 10 * time.Second
+	retryablehttpOptions.RetryMax = key.retries
+
+	client := retryablehttp.NewWithHTTPClient(&http.Client{
+		Transport:     transport,
+		Timeout:       time.Duration(key.timeout) * time.Second,
+		CheckRedirect: makeCheckRedirectFunc(key.followRedirects, key.maxRedirects),
+		Jar:           jar,
+	}, retryablehttpOptions)
+	// nolint:bodyclose // false positive there is no body to close yet
+	client.CheckRetry = retryablehttp.HostSprayRetryPolicy()
+
+	if jar == nil {
+		p.clients[key] = client
+	}
+
+	return client, nil
+}
+
+// newTransport creates the transport for a proxy configuration
+func (p *HTTPClientPool) newTransport(key transportKey) (*http.Transport, error) {
+	// The dial function is the socks proxied version if a socks proxy is specified
+	dialer, err := newDialer(30*time.Second, key.proxySocksURL)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		MaxIdleConnsPerHost: -1,
+		MaxConnsPerHost:     p.options.MaxConnsPerHost,
+		TLSClientConfig: &tls.Config{
+			Renegotiation:      tls.RenegotiateOnceAsClient,
+			InsecureSkipVerify: true,
+		},
+		DisableKeepAlives: !p.options.KeepAlive,
+	}
+
+	if p.options.KeepAlive {
+		transport.MaxIdleConns = 0
+		transport.MaxIdleConnsPerHost = p.options.MaxConnsPerHost
+		transport.IdleConnTimeout = 30 * time.Second
+
+		if transport.MaxIdleConnsPerHost == 0 {
+			transport.MaxIdleConnsPerHost = ten
+		}
+	}
+
+	if key.proxyURL != "" {
+		proxyURL, err := url.Parse(key.proxyURL)
+		if err != nil {
+			return nil, err
+		}
+
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return transport, nil
+}
@@ -0,0 +1,63 @@
+package executer
+
+import "github.com/projectdiscovery/gologger"
+
+// Logger logs the progress and the warnings of the executers. A single logger
+// is shared by all the executers so it must be safe for concurrent use.
+type Logger interface {
+	// Verbosef logs a verbose message with a label
+	Verbosef(format string, label string, args ...interface{})
+	// Infof logs an informational message, like the requests dumped in debug mode
+	Infof(format string, args ...interface{})
+	// Warningf logs a warning
+	Warningf(format string, args ...interface{})
+	// Errorf logs an error
+	Errorf(format string, args ...interface{})
+}
+
+// defaultLogger logs the messages with the global logger of the command line
+type defaultLogger struct{}
+
+// Verbosef logs a verbose message with a label
+func (defaultLogger) Verbosef(format string, label string, args ...interface{}) {
+	gologger.Verbosef(format, label, args...)
+}
+
+// Infof logs an informational message
+func (defaultLogger) Infof(format string, args ...interface{}) {
+	gologger.Infof(format, args...)
+}
+
+// Warningf logs a warning
+func (defaultLogger) Warningf(format string, args ...interface{}) {
+	gologger.Warningf(format, args...)
+}
+
+// Errorf logs an error
+func (defaultLogger) Errorf(format string, args ...interface{}) {
+	gologger.Errorf(format, args...)
+}
+
+// NoOpLogger is a logger discarding all the messages
+type NoOpLogger struct{}
+
+// Verbosef does nothing
+func (NoOpLogger) Verbosef(format string, label string, args ...interface{}) {}
+
+// Infof does nothing
+func (NoOpLogger) Infof(format string, args ...interface{}) {}
+
+// Warningf does nothing
+func (NoOpLogger) Warningf(format string, args ...interface{}) {}
+
+// Errorf does nothing
+func (NoOpLogger) Errorf(format string, args ...interface{}) {}
+
+// loggerOrDefault returns the logger, or the global logger if it is nil
+func loggerOrDefault(logger Logger) Logger {
+	if logger == nil {
+		return defaultLogger{}
+	}
+
+	return logger
+}
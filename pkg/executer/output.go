@@ -0,0 +1,282 @@
+package executer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/logrusorgru/aurora"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// ResultEvent is a result found by a template on a target
+type ResultEvent struct {
+	// Template is the ID of the template
+	Template string `json:"template"`
+	// Info contains the information about the template
+	templates.Info
+	// Type is the type of the request, e.g. http or dns
+	Type string `json:"type"`
+	// Host is the target the template was run on
+	Host string `json:"host"`
+	// Matched is the location the result was found at
+	Matched string `json:"matched"`
+	// MatcherName is the name of the matcher that matched, if any
+	MatcherName string `json:"matcher_name,omitempty"`
+	// ExtractedResults contains the values extracted by the extractors
+	ExtractedResults []string `json:"extracted_results,omitempty"`
+	// Meta contains the payload values used for the request
+	Meta map[string]interface{} `json:"meta,omitempty"`
+	// Request is the request sent, if requests are written
+	Request string `json:"request,omitempty"`
+	// Response is the response received, if requests are written
+	Response string `json:"response,omitempty"`
+	// Timestamp is the time the result was found at
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// OutputWriter writes the results found by the templates. A single writer
+// is shared by all the executers so it must be safe for concurrent use.
+type OutputWriter interface {
+	// Write writes a result event
+	Write(event *ResultEvent) error
+	// Close flushes any pending output
+	Close() error
+}
+
+// newResultEvent creates a result event for a template
+func newResultEvent(template *templates.Template, requestType, host, matched string, matcher *matchers.Matcher, extractorResults []string) *ResultEvent {
+	event := &ResultEvent{
+		Template:         template.ID,
+		Info:             template.Info,
+		Type:             requestType,
+		Host:             host,
+		Matched:          matched,
+		ExtractedResults: extractorResults,
+		Timestamp:        time.Now(),
+	}
+
+	if matcher != nil {
+		event.MatcherName = matcher.Name
+	}
+
+	return event
+}
+
+// writeResultEvent writes a result event, logging any error
+func writeResultEvent(writer OutputWriter, logger Logger, event *ResultEvent) {
+	if writer == nil {
+		return
+	}
+
+	if err := writer.Write(event); err != nil {
+		logger.Errorf("Could not write output data: %s\n", err)
+	}
+}
+
+// textWriter writes the results as text lines on the screen and to an optional output
+type textWriter struct {
+	output      *bufio.Writer
+	colorizer   aurora.Aurora
+	decolorizer *regexp.Regexp
+	mutex       sync.Mutex
+}
+
+// NewTextWriter creates a writer printing the results as colored text lines on the
+// screen. The lines are also written without colors to the output, if not nil.
+func NewTextWriter(output io.Writer, colorizer aurora.Aurora, decolorizer *regexp.Regexp) OutputWriter {
+	writer := &textWriter{colorizer: colorizer, decolorizer: decolorizer}
+	if output != nil {
+		writer.output = bufio.NewWriter(output)
+	}
+
+	return writer
+}
+
+// Write writes a result event as a text line
+func (w *textWriter) Write(event *ResultEvent) error {
+	builder := &strings.Builder{}
+	colorizer := w.colorizer
+
+	builder.WriteRune('[')
+	builder.WriteString(colorizer.BrightGreen(event.Template).String())
+
+	if event.MatcherName != "" {
+		builder.WriteString(":")
+		builder.WriteString(colorizer.BrightGreen(event.MatcherName).Bold().String())
+	}
+
+	builder.WriteString("] [")
+	builder.WriteString(colorizer.BrightBlue(event.Type).String())
+	builder.WriteString("] ")
+	builder.WriteString(event.Matched)
+
+	// If any extractors, write the results
+	if len(event.ExtractedResults) > 0 {
+		builder.WriteString(" [")
+
+		for i, result := range event.ExtractedResults {
+			builder.WriteString(colorizer.BrightCyan(result).String())
+
+			if i != len(event.ExtractedResults)-1 {
+				builder.WriteRune(',')
+			}
+		}
+
+		builder.WriteString("]")
+	}
+
+	// write meta if any
+	if len(event.Meta) > 0 {
+		builder.WriteString(" [")
+
+		var metas []string
+
+		for name, value := range event.Meta {
+			metas = append(metas, colorizer.BrightYellow(name).Bold().String()+"="+colorizer.BrightYellow(fmt.Sprint(value)).String())
+		}
+
+		sort.Strings(metas)
+		builder.WriteString(strings.Join(metas, ","))
+		builder.WriteString("]")
+	}
+
+	builder.WriteRune('\n')
+
+	message := builder.String()
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	// Write output to screen as well as any output file
+	gologger.Silentf("%s", message)
+
+	if w.output == nil {
+		return nil
+	}
+
+	if w.decolorizer != nil {
+		message = w.decolorizer.ReplaceAllString(message, "")
+	}
+
+	_, err := w.output.WriteString(message)
+
+	return err
+}
+
+// Close flushes the output of the writer
+func (w *textWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.output == nil {
+		return nil
+	}
+
+	return w.output.Flush()
+}
+
+// jsonWriter writes the results as json lines on the screen and to an optional output
+type jsonWriter struct {
+	output   *bufio.Writer
+	requests bool
+	mutex    sync.Mutex
+}
+
+// NewJSONWriter creates a writer printing the results as json lines on the screen. The lines
+// are also written to the output, if not nil. Requests and responses of the results are
+// only written if requests is true.
+func NewJSONWriter(output io.Writer, requests bool) OutputWriter {
+	writer := &jsonWriter{requests: requests}
+	if output != nil {
+		writer.output = bufio.NewWriter(output)
+	}
+
+	return writer
+}
+
+// Write writes a result event as a json line
+func (w *jsonWriter) Write(event *ResultEvent) error {
+	if !w.requests && (event.Request != "" || event.Response != "") {
+		withoutRequests := *event
+		withoutRequests.Request = ""
+		withoutRequests.Response = ""
+		event = &withoutRequests
+	}
+
+	data, err := jsoniter.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	gologger.Silentf("%s\n", string(data))
+
+	if w.output == nil {
+		return nil
+	}
+
+	if _, err := w.output.Write(data); err != nil {
+		return err
+	}
+
+	return w.output.WriteByte('\n')
+}
+
+// Close flushes the output of the writer
+func (w *jsonWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.output == nil {
+		return nil
+	}
+
+	return w.output.Flush()
+}
+
+// multiWriter writes the results to several writers
+type multiWriter struct {
+	writers []OutputWriter
+}
+
+// NewMultiWriter creates a writer writing the results to all the given writers
+func NewMultiWriter(writers ...OutputWriter) OutputWriter {
+	return &multiWriter{writers: writers}
+}
+
+// Write writes a result event to all the writers, returning the first error
+func (w *multiWriter) Write(event *ResultEvent) error {
+	var firstErr error
+
+	for _, writer := range w.writers {
+		if err := writer.Write(event); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// Close closes all the writers, returning the first error
+func (w *multiWriter) Close() error {
+	var firstErr error
+
+	for _, writer := range w.writers {
+		if err := writer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,14 @@
+package executer
+
+import "github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+
+// writeOutputFile writes file output to streams
+func (e *FileExecuter) writeOutputFile(input, location, data string, matcher *matchers.Matcher, extractorResults []string) {
+	event := newResultEvent(e.template, "file", input, location, matcher, extractorResults)
+
+	if e.jsonRequest {
+		event.Response = data
+	}
+
+	writeResultEvent(e.writer, e.logger, event)
+}
@@ -0,0 +1,15 @@
+package executer
+
+import "github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+
+// writeOutputNetwork writes network output to streams
+func (e *NetworkExecuter) writeOutputNetwork(reqURL, address, req, resp string, matcher *matchers.Matcher, extractorResults []string) {
+	event := newResultEvent(e.template, "network", reqURL, address, matcher, extractorResults)
+
+	if e.jsonRequest {
+		event.Request = req
+		event.Response = resp
+	}
+
+	writeResultEvent(e.writer, e.logger, event)
+}
@@ -0,0 +1,14 @@
+package executer
+
+import "github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+
+// writeOutputSSL writes ssl output to streams
+func (e *SSLExecuter) writeOutputSSL(reqURL, address, resp string, matcher *matchers.Matcher, extractorResults []string) {
+	event := newResultEvent(e.template, "ssl", reqURL, address, matcher, extractorResults)
+
+	if e.jsonRequest {
+		event.Response = resp
+	}
+
+	writeResultEvent(e.writer, e.logger, event)
+}
@@ -0,0 +1,21 @@
+package executer
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+)
+
+// writeOutputWebSocket writes websocket output to streams
+func (e *WebSocketExecuter) writeOutputWebSocket(reqURL string, request *requests.CompiledWebSocketRequest, resp *http.Response, sent, body string, matcher *matchers.Matcher, extractorResults []string) {
+	event := newResultEvent(e.template, "websocket", reqURL, request.URL, matcher, extractorResults)
+
+	if e.jsonRequest {
+		event.Request = fmt.Sprintf("GET %s\n%s\n%s", request.URL, headersToString(request.Headers), sent)
+		event.Response = fmt.Sprintf("%s\n%s\n%s", resp.Status, headersToString(resp.Header), body)
+	}
+
+	writeResultEvent(e.writer, e.logger, event)
+}
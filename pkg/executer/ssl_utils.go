@@ -0,0 +1,139 @@
+package executer
+
+import (
+	"crypto/sha1" // nolint:gosec // used for certificate fingerprints only
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+)
+
+const hoursPerDay = 24
+
+// sslToMap converts the result of a tls handshake to a map usable by matchers and extractors
+func sslToMap(address, serverName string, state *tls.ConnectionState, handshakeErr error) map[string]interface{} {
+	m := make(map[string]interface{})
+
+	host, port, _ := net.SplitHostPort(address)
+	m["host"] = host
+	m["port"] = port
+	m["server_name"] = serverName
+	m["handshake"] = handshakeErr == nil
+
+	if handshakeErr != nil {
+		m["error"] = handshakeErr.Error()
+	}
+
+	if state != nil && handshakeErr == nil {
+		m["tls_version"] = requests.TLSVersionToString(state.Version)
+		m["cipher_suite"] = tls.CipherSuiteName(state.CipherSuite)
+		m["chain_length"] = len(state.PeerCertificates)
+
+		if len(state.PeerCertificates) > 0 {
+			certificateToMap(m, serverName, state.PeerCertificates)
+		}
+	}
+
+	m["raw"] = sslMapToString(m)
+
+	return m
+}
+
+// certificateToMap adds the fields of the leaf certificate of a chain to the map
+func certificateToMap(m map[string]interface{}, serverName string, chain []*x509.Certificate) {
+	leaf := chain[0]
+	now := time.Now()
+
+	m["subject_cn"] = leaf.Subject.CommonName
+	m["subject_dn"] = leaf.Subject.String()
+	m["subject_org"] = strings.Join(leaf.Subject.Organization, ",")
+	m["issuer_cn"] = leaf.Issuer.CommonName
+	m["issuer_dn"] = leaf.Issuer.String()
+	m["issuer_org"] = strings.Join(leaf.Issuer.Organization, ",")
+
+	var sans []string
+
+	sans = append(sans, leaf.DNSNames...)
+	sans = append(sans, leaf.EmailAddresses...)
+
+	for _, ip := range leaf.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	m["subject_an"] = strings.Join(sans, ",")
+	m["serial"] = strings.ToLower(leaf.SerialNumber.Text(16))
+	m["not_before"] = leaf.NotBefore.Unix()
+	m["not_after"] = leaf.NotAfter.Unix()
+	m["days_to_expiry"] = int(math.Floor(leaf.NotAfter.Sub(now).Hours() / hoursPerDay))
+	m["expired"] = now.After(leaf.NotAfter)
+	m["not_yet_valid"] = now.Before(leaf.NotBefore)
+	m["self_signed"] = isSelfSigned(leaf)
+	m["mismatched"] = serverName != "" && leaf.VerifyHostname(serverName) != nil
+	m["untrusted"] = !isTrusted(chain)
+	m["signature_algorithm"] = leaf.SignatureAlgorithm.String()
+	m["public_key_algorithm"] = leaf.PublicKeyAlgorithm.String()
+
+	sha256Fingerprint := sha256.Sum256(leaf.Raw)
+	m["fingerprint_sha256"] = hex.EncodeToString(sha256Fingerprint[:])
+
+	sha1Fingerprint := sha1.Sum(leaf.Raw) // nolint:gosec // used for certificate fingerprints only
+	m["fingerprint_sha1"] = hex.EncodeToString(sha1Fingerprint[:])
+
+	var subjects []string
+	for _, certificate := range chain {
+		subjects = append(subjects, certificate.Subject.String())
+	}
+
+	m["chain"] = strings.Join(subjects, "\n")
+}
+
+// isSelfSigned checks if a certificate has been signed by its own key
+func isSelfSigned(certificate *x509.Certificate) bool {
+	if certificate.Subject.String() != certificate.Issuer.String() {
+		return false
+	}
+
+	return certificate.CheckSignatureFrom(certificate) == nil
+}
+
+// isTrusted verifies the certificate chain against the system roots
+func isTrusted(chain []*x509.Certificate) bool {
+	intermediates := x509.NewCertPool()
+	for _, certificate := range chain[1:] {
+		intermediates.AddCert(certificate)
+	}
+
+	_, err := chain[0].Verify(x509.VerifyOptions{Intermediates: intermediates})
+
+	return err == nil
+}
+
+// sslMapToString converts the map to a key: value text representation
+// on which words and regex matchers can be applied.
+func sslMapToString(m map[string]interface{}) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	builder := &strings.Builder{}
+
+	for _, k := range keys {
+		builder.WriteString(k)
+		builder.WriteString(": ")
+		builder.WriteString(strings.ReplaceAll(fmt.Sprint(m[k]), "\n", ","))
+		builder.WriteRune('\n')
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,85 @@
+package executer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// handshakeState performs a tls handshake with the server without verifying its certificate
+func handshakeState(t *testing.T, address, serverName string) *tls.ConnectionState {
+	conn, err := tls.Dial("tcp", address, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true, // nolint:gosec // the certificate is checked by the tests
+		MaxVersion:         tls.VersionTLS12,
+		CipherSuites:       []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+	})
+	require.Nil(t, err, "Could not perform tls handshake")
+
+	defer conn.Close()
+
+	state := conn.ConnectionState()
+
+	return &state
+}
+
+func TestSSLToMap(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	address := server.Listener.Addr().String()
+	certificate := server.Certificate()
+
+	// The certificate of the test server is self-signed for example.com
+	m := sslToMap(address, "example.com", handshakeState(t, address, "example.com"), nil)
+	require.Equal(t, true, m["handshake"], "Could not get handshake status")
+	require.Equal(t, "tls12", m["tls_version"], "Could not get tls version")
+	require.Equal(t, "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", m["cipher_suite"], "Could not get cipher suite")
+	require.Equal(t, 1, m["chain_length"], "Could not get chain length")
+	require.Equal(t, false, m["expired"], "Could get expired for valid certificate")
+	require.Equal(t, false, m["not_yet_valid"], "Could get not yet valid for valid certificate")
+	require.Equal(t, true, m["self_signed"], "Could not get self signed certificate")
+	require.Equal(t, true, m["untrusted"], "Could not get untrusted certificate")
+	require.Equal(t, false, m["mismatched"], "Could get mismatched for certificate name")
+	require.Equal(t, "example.com", m["server_name"], "Could not get server name")
+	require.Equal(t, strings.ToLower(certificate.SerialNumber.Text(16)), m["serial"], "Could not get serial")
+	require.Equal(t, certificate.NotAfter.Unix(), m["not_after"], "Could not get expiry")
+	require.Contains(t, m["subject_an"], "example.com", "Could not get subject alternative names")
+	require.Contains(t, m["raw"], "self_signed: true\n", "Could not get raw representation")
+
+	expected := int(math.Floor(time.Until(certificate.NotAfter).Hours() / hoursPerDay))
+	require.InDelta(t, expected, m["days_to_expiry"], 1, "Could not get days to expiry")
+
+	m = sslToMap(address, "nuclei.test", handshakeState(t, address, "nuclei.test"), nil)
+	require.Equal(t, true, m["mismatched"], "Could not get mismatched certificate name")
+
+	m = sslToMap(address, "", handshakeState(t, address, ""), nil)
+	require.Equal(t, false, m["mismatched"], "Could get mismatched without server name")
+
+	m = sslToMap(address, "example.com", &tls.ConnectionState{}, errors.New("handshake failure"))
+	require.Equal(t, false, m["handshake"], "Could get handshake status of failed handshake")
+	require.Equal(t, "handshake failure", m["error"], "Could not get handshake error")
+	require.NotContains(t, m, "tls_version", "Could get tls version of failed handshake")
+}
+
+func TestCertificateToMapExpired(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	expired := *server.Certificate()
+	expired.NotBefore = time.Now().Add(-48 * time.Hour)
+	expired.NotAfter = time.Now().Add(-36 * time.Hour)
+
+	m := make(map[string]interface{})
+	certificateToMap(m, "example.com", []*x509.Certificate{&expired})
+	require.Equal(t, true, m["expired"], "Could not get expired certificate")
+	require.Equal(t, -2, m["days_to_expiry"], "Could not get days since expiry")
+}
@@ -3,6 +3,12 @@ package extractors
 import (
 	"fmt"
 	"regexp"
+	"strconv"
+
+	"github.com/Knetic/govaluate"
+	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/query"
 )
 
 // CompileExtractors performs the initial setup operation on a extractor
@@ -22,8 +28,53 @@ func (e *Extractor) CompileExtractors() error {
 		}
 
 		e.regexCompiled = append(e.regexCompiled, compiled)
+
+		index, err := groupIndex(compiled, e.Group)
+		if err != nil {
+			return fmt.Errorf("could not find group %s in regex: %s", e.Group, regex)
+		}
+
+		e.groupIndexes = append(e.groupIndexes, index)
+	}
+
+	// Compile the json queries
+	for _, jsonQuery := range e.JSON {
+		compiled, err := query.ParseJSON(jsonQuery)
+		if err != nil {
+			return fmt.Errorf("could not compile json query: %s", jsonQuery)
+		}
+
+		e.jsonCompiled = append(e.jsonCompiled, compiled)
+	}
+
+	// Compile the xpath queries
+	for _, xpath := range e.XPath {
+		compiled, err := query.ParseXPath(xpath)
+		if err != nil {
+			return fmt.Errorf("could not compile xpath: %s", xpath)
+		}
+
+		e.xpathCompiled = append(e.xpathCompiled, compiled)
+	}
+
+	// Compile the dsl expressions
+	for _, dsl := range e.DSL {
+		compiled, err := govaluate.NewEvaluableExpressionWithFunctions(dsl, generators.HelperFunctions())
+		if err != nil {
+			return fmt.Errorf("could not compile dsl: %s", dsl)
+		}
+
+		e.dslCompiled = append(e.dslCompiled, compiled)
+	}
+
+	// Parse the dns record types
+	recordTypes, err := matchers.ParseDNSRecordTypes(e.RecordTypes)
+	if err != nil {
+		return err
 	}
 
+	e.recordTypes = recordTypes
+
 	// Setup the part of the request to match, if any.
 	if e.Part != "" {
 		e.part, ok = PartTypes[e.Part]
@@ -36,3 +87,26 @@ func (e *Extractor) CompileExtractors() error {
 
 	return nil
 }
+
+// groupIndex returns the index of a capture group given by number or name in a regex
+func groupIndex(regex *regexp.Regexp, group string) (int, error) {
+	if group == "" {
+		return 0, nil
+	}
+
+	if index, err := strconv.Atoi(group); err == nil {
+		if index < 0 || index > regex.NumSubexp() {
+			return 0, fmt.Errorf("no group %d", index)
+		}
+
+		return index, nil
+	}
+
+	for index, name := range regex.SubexpNames() {
+		if name == group {
+			return index, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no group %s", group)
+}
@@ -1,13 +1,22 @@
 package extractors
 
 import (
+	"fmt"
+	"mime"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/miekg/dns"
+	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/query"
 )
 
-// Extract extracts response from the parts of request using a regex
-func (e *Extractor) Extract(resp *http.Response, body, headers string) map[string]struct{} {
+// Extract extracts response from the parts of request using a regex. The values are
+// the variables extracted before, made available to dsl expressions with the response.
+func (e *Extractor) Extract(resp *http.Response, body, headers string, values map[string]interface{}) map[string]struct{} {
 	switch e.extractorType {
 	case RegexExtractor:
 		if e.part == BodyPart {
@@ -33,37 +42,206 @@ func (e *Extractor) Extract(resp *http.Response, body, headers string) map[strin
 		}
 
 		return e.extractCookieKVal(resp)
+	case JSONExtractor:
+		return e.extractJSON(body)
+	case XPathExtractor:
+		return e.extractXPath(body, matchers.ContentType(resp))
+	case DSLExtractor:
+		return e.extractDSL(generators.MergeMaps(values, matchers.HTTPToMap(resp, body, headers)))
 	}
 
 	return nil
 }
 
-// ExtractDNS extracts response from dns message using a regex
+// ExtractDNS extracts response from dns message using a regex, or the values of its records.
+// Only the records of the part and types selected by the extractor are used, if any.
 // nolint:interfacer // dns.Msg is out of current scope
-func (e *Extractor) ExtractDNS(msg *dns.Msg) map[string]struct{} {
+func (e *Extractor) ExtractDNS(msg *dns.Msg, values map[string]interface{}) map[string]struct{} {
+	if msg == nil {
+		return nil
+	}
+
+	switch e.extractorType {
+	case RegexExtractor:
+		return e.extractRegex(matchers.DNSCorpus(msg, e.dnsSection(), e.recordTypes))
+	case RecordExtractor:
+		return e.extractRecords(msg)
+	case KValExtractor:
+	case DSLExtractor:
+		return e.extractDSL(generators.MergeMaps(values, matchers.DNSToMap(msg)))
+	}
+
+	return nil
+}
+
+// ExtractNetwork extracts response from data received from a network connection using a regex
+func (e *Extractor) ExtractNetwork(data string, values map[string]interface{}) map[string]struct{} {
+	switch e.extractorType {
+	case RegexExtractor:
+		return e.extractRegex(data)
+	case KValExtractor:
+	case JSONExtractor:
+		return e.extractJSON(data)
+	case XPathExtractor:
+		return e.extractXPath(data, "")
+	case DSLExtractor:
+		return e.extractDSL(generators.MergeMaps(values, matchers.NetworkToMap(data)))
+	}
+
+	return nil
+}
+
+// ExtractFile extracts response from the content of a file using a regex. The values are
+// the variables extracted before, made available to dsl expressions with the file.
+func (e *Extractor) ExtractFile(path, data string, values map[string]interface{}) map[string]struct{} {
+	switch e.extractorType {
+	case RegexExtractor:
+		return e.extractRegex(data)
+	case KValExtractor:
+	case JSONExtractor:
+		return e.extractJSON(data)
+	case XPathExtractor:
+		return e.extractXPath(data, mime.TypeByExtension(filepath.Ext(path)))
+	case DSLExtractor:
+		return e.extractDSL(generators.MergeMaps(values, matchers.FileToMap(path, data)))
+	}
+
+	return nil
+}
+
+// ExtractSSL extracts response from the details of a tls handshake
+func (e *Extractor) ExtractSSL(data, values map[string]interface{}) map[string]struct{} {
 	switch e.extractorType {
 	case RegexExtractor:
-		return e.extractRegex(msg.String())
+		raw, _ := data["raw"].(string)
+		return e.extractRegex(raw)
 	case KValExtractor:
+		return e.extractMapKVal(data)
+	case DSLExtractor:
+		return e.extractDSL(generators.MergeMaps(values, data))
 	}
 
 	return nil
 }
 
-// extractRegex extracts text from a corpus and returns it
+// extractRegex extracts text from a corpus and returns it, or only the selected group of the matches
 func (e *Extractor) extractRegex(corpus string) map[string]struct{} {
 	results := make(map[string]struct{})
 
-	for _, regex := range e.regexCompiled {
-		matches := regex.FindAllString(corpus, -1)
-		for _, match := range matches {
-			results[match] = struct{}{}
+	for i, regex := range e.regexCompiled {
+		group := e.groupIndexes[i]
+
+		for _, match := range regex.FindAllStringSubmatch(corpus, -1) {
+			// Skip the groups which did not participate in the match
+			if group > 0 && match[group] == "" {
+				continue
+			}
+
+			results[match[group]] = struct{}{}
+		}
+	}
+
+	return results
+}
+
+// extractJSON extracts the values selected by the json queries in a json document
+func (e *Extractor) extractJSON(corpus string) map[string]struct{} {
+	results := make(map[string]struct{})
+
+	document, err := query.ParseJSONDocument(corpus)
+	if err != nil {
+		return results
+	}
+
+	for _, jsonQuery := range e.jsonCompiled {
+		for _, value := range jsonQuery.Run(document) {
+			if result, ok := query.JSONValueToString(value); ok {
+				results[result] = struct{}{}
+			}
+		}
+	}
+
+	return results
+}
+
+// extractXPath extracts the text or the attribute of the nodes selected
+// by the xpath queries in a html or xml document of the given content type, if known.
+func (e *Extractor) extractXPath(corpus, contentType string) map[string]struct{} {
+	results := make(map[string]struct{})
+
+	document, err := query.ParseDocument(corpus, contentType)
+	if err != nil {
+		return results
+	}
+
+	for _, xpath := range e.xpathCompiled {
+		for _, node := range xpath.Select(document) {
+			if e.Attribute == "" {
+				if text := strings.TrimSpace(query.Text(node)); text != "" {
+					results[text] = struct{}{}
+				}
+
+				continue
+			}
+
+			if value, ok := query.Attribute(node, e.Attribute); ok {
+				results[value] = struct{}{}
+			}
+		}
+	}
+
+	return results
+}
+
+// extractDSL extracts the results of the dsl expressions evaluated on the fields of a response.
+// Expressions which fail or return nil are skipped.
+func (e *Extractor) extractDSL(data map[string]interface{}) map[string]struct{} {
+	results := make(map[string]struct{})
+
+	for _, expression := range e.dslCompiled {
+		result, err := expression.Evaluate(data)
+		if err != nil || result == nil {
+			continue
+		}
+
+		switch value := result.(type) {
+		case float64:
+			results[strconv.FormatFloat(value, 'f', -1, 64)] = struct{}{}
+		case []byte:
+			results[string(value)] = struct{}{}
+		default:
+			results[fmt.Sprint(value)] = struct{}{}
 		}
 	}
 
 	return results
 }
 
+// extractRecords extracts the values of the records of a dns response
+func (e *Extractor) extractRecords(msg *dns.Msg) map[string]struct{} {
+	results := make(map[string]struct{})
+
+	for _, record := range matchers.DNSRecords(msg, e.dnsSection(), e.recordTypes) {
+		results[matchers.DNSRecordValue(record)] = struct{}{}
+	}
+
+	return results
+}
+
+// dnsSection returns the section of a dns response the part of the extractor selects
+func (e *Extractor) dnsSection() matchers.DNSSection {
+	switch e.part {
+	case AnswerPart:
+		return matchers.AnswerSection
+	case AuthorityPart:
+		return matchers.AuthoritySection
+	case AdditionalPart:
+		return matchers.AdditionalSection
+	}
+
+	return matchers.AllSections
+}
+
 // extractKVal extracts text from http response
 func (e *Extractor) extractKVal(r *http.Response) map[string]struct{} {
 	results := make(map[string]struct{})
@@ -77,6 +255,19 @@ func (e *Extractor) extractKVal(r *http.Response) map[string]struct{} {
 	return results
 }
 
+// extractMapKVal extracts values from a map of response fields
+func (e *Extractor) extractMapKVal(data map[string]interface{}) map[string]struct{} {
+	results := make(map[string]struct{})
+
+	for _, k := range e.KVal {
+		if v, ok := data[k]; ok {
+			results[fmt.Sprint(v)] = struct{}{}
+		}
+	}
+
+	return results
+}
+
 // extractCookieKVal extracts text from cookies
 func (e *Extractor) extractCookieKVal(r *http.Response) map[string]struct{} {
 	results := make(map[string]struct{})
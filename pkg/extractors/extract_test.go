@@ -0,0 +1,93 @@
+package extractors
+
+import (
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+// sortedResults returns the extracted results in order
+func sortedResults(results map[string]struct{}) []string {
+	sorted := make([]string, 0, len(results))
+	for result := range results {
+		sorted = append(sorted, result)
+	}
+
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+func TestRegexGroups(t *testing.T) {
+	tests := []struct {
+		regex    string
+		group    string
+		data     string
+		expected []string
+	}{
+		{`version=(\d+)\.(\d+)`, "", "version=1.2 version=3.4", []string{"version=1.2", "version=3.4"}},
+		{`version=(\d+)\.(\d+)`, "0", "version=1.2", []string{"version=1.2"}},
+		{`version=(\d+)\.(\d+)`, "1", "version=1.2 version=3.4", []string{"1", "3"}},
+		{`version=(\d+)\.(\d+)`, "2", "version=1.2 version=3.4", []string{"2", "4"}},
+		{`token="(?P<token>[a-f0-9]+)"`, "token", `token="abc123" token="def456"`, []string{"abc123", "def456"}},
+		// Matches where the group did not participate are skipped
+		{`id=(\d+)|name=(\w+)`, "2", "id=1 name=admin id=2", []string{"admin"}},
+		{`id=(?P<id>\d+)|name=(?P<name>\w+)`, "id", "id=1 name=admin id=2", []string{"1", "2"}},
+	}
+
+	for _, test := range tests {
+		e := &Extractor{Type: "regex", Regex: []string{test.regex}, Group: test.group}
+		require.Nil(t, e.CompileExtractors(), "Could not compile regex %s with group %s", test.regex, test.group)
+
+		results := e.ExtractNetwork(test.data, nil)
+		require.Equal(t, test.expected, sortedResults(results), "Could not extract group %s of %s", test.group, test.regex)
+	}
+}
+
+func TestInvalidRegexGroups(t *testing.T) {
+	for _, group := range []string{"3", "-1", "missing"} {
+		e := &Extractor{Type: "regex", Regex: []string{`version=(\d+)\.(?P<minor>\d+)`}, Group: group}
+		require.NotNil(t, e.CompileExtractors(), "Could compile regex with group %s", group)
+	}
+}
+
+func TestDSLExtractor(t *testing.T) {
+	values := map[string]interface{}{"token": "abc"}
+
+	e := &Extractor{Type: "dsl", DSL: []string{`concat(token, ":", status_code)`, `toupper(x_powered_by)`, `unknown_field`}}
+	require.Nil(t, e.CompileExtractors(), "Could not compile dsl extractor")
+
+	resp := &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"X-Powered-By": []string{"php"}},
+		Body:       ioutil.NopCloser(strings.NewReader("body")),
+	}
+
+	results := e.Extract(resp, "body", "X-Powered-By: php\n", values)
+	require.Equal(t, []string{"PHP", "abc:200"}, sortedResults(results), "Could not extract from http response with values")
+
+	e = &Extractor{Type: "dsl", DSL: []string{`concat(token, ":", rcode)`, `contains(answer, "10.0.0.1")`}}
+	require.Nil(t, e.CompileExtractors(), "Could not compile dsl extractor")
+
+	msg := &dns.Msg{}
+	msg.SetQuestion("www.example.com.", dns.TypeA)
+
+	record, err := dns.NewRR("www.example.com. 300 IN A 10.0.0.1")
+	require.Nil(t, err, "Could not parse dns record")
+
+	msg.Answer = append(msg.Answer, record)
+
+	results = e.ExtractDNS(msg, values)
+	require.Equal(t, []string{"abc:0", "true"}, sortedResults(results), "Could not extract from dns response with values")
+
+	e = &Extractor{Type: "dsl", DSL: []string{`concat(token, ":", path)`}}
+	require.Nil(t, e.CompileExtractors(), "Could not compile dsl extractor")
+
+	results = e.ExtractFile("config.php", "data", values)
+	require.Equal(t, []string{"abc:config.php"}, sortedResults(results), "Could not extract from file with values")
+}
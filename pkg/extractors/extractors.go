@@ -1,6 +1,11 @@
 package extractors
 
-import "regexp"
+import (
+	"regexp"
+
+	"github.com/Knetic/govaluate"
+	"github.com/projectdiscovery/nuclei/v2/pkg/query"
+)
 
 // Extractor is used to extract part of response using a regex.
 type Extractor struct {
@@ -15,10 +20,42 @@ type Extractor struct {
 	Regex []string `yaml:"regex"`
 	// regexCompiled is the compiled variant
 	regexCompiled []*regexp.Regexp
+	// Group is the number or the name of the capture group of the regex to extract.
+	//
+	// By default, the whole match is extracted.
+	Group string `yaml:"group,omitempty"`
+	// groupIndexes are the indexes of the group in each compiled regex
+	groupIndexes []int
 
 	// KVal are the kval to be present in the response headers/cookies
 	KVal []string `yaml:"kval,omitempty"`
 
+	// JSON are the jq-style queries to run on the response body
+	JSON []string `yaml:"json,omitempty"`
+	// jsonCompiled is the compiled variant
+	jsonCompiled []*query.JSONQuery
+
+	// XPath are the xpath queries to run on the html or xml response body
+	XPath []string `yaml:"xpath,omitempty"`
+	// xpathCompiled is the compiled variant
+	xpathCompiled []*query.XPath
+	// DSL are the dsl expressions whose results are extracted
+	DSL []string `yaml:"dsl,omitempty"`
+	// dslCompiled is the compiled variant
+	dslCompiled []*govaluate.EvaluableExpression
+
+	// RecordTypes are the types of the dns records to extract from, e.g. CNAME
+	//
+	// By default, all the records are used.
+	RecordTypes []string `yaml:"record-types,omitempty"`
+	// recordTypes is the compiled variant
+	recordTypes []uint16
+
+	// Attribute is the optional attribute to extract from the nodes selected by xpath.
+	//
+	// By default, the text of the nodes is extracted.
+	Attribute string `yaml:"attribute,omitempty"`
+
 	// Part is the part of the request to match
 	//
 	// By default, matching is performed in request body.
@@ -38,12 +75,24 @@ const (
 	RegexExtractor ExtractorType = iota + 1
 	// KValExtractor extracts responses with key:value
 	KValExtractor
+	// JSONExtractor extracts responses with jq-style queries
+	JSONExtractor
+	// XPathExtractor extracts responses with xpath queries
+	XPathExtractor
+	// DSLExtractor extracts the results of dsl expressions
+	DSLExtractor
+	// RecordExtractor extracts the values of the records of dns responses
+	RecordExtractor
 )
 
 // ExtractorTypes is an table for conversion of extractor type from string.
 var ExtractorTypes = map[string]ExtractorType{
-	"regex": RegexExtractor,
-	"kval":  KValExtractor,
+	"regex":  RegexExtractor,
+	"kval":   KValExtractor,
+	"json":   JSONExtractor,
+	"xpath":  XPathExtractor,
+	"dsl":    DSLExtractor,
+	"record": RecordExtractor,
 }
 
 // Part is the part of the request to match
@@ -56,13 +105,22 @@ const (
 	HeaderPart
 	// AllPart matches both response body and headers of the response.
 	AllPart
+	// AnswerPart matches the answer section of a dns response.
+	AnswerPart
+	// AuthorityPart matches the authority section of a dns response.
+	AuthorityPart
+	// AdditionalPart matches the additional section of a dns response.
+	AdditionalPart
 )
 
 // PartTypes is an table for conversion of part type from string.
 var PartTypes = map[string]Part{
-	"body":   BodyPart,
-	"header": HeaderPart,
-	"all":    AllPart,
+	"body":       BodyPart,
+	"header":     HeaderPart,
+	"all":        AllPart,
+	"answer":     AnswerPart,
+	"authority":  AuthorityPart,
+	"additional": AdditionalPart,
 }
 
 // GetPart returns the part of the matcher
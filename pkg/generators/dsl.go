@@ -1,17 +1,38 @@
 package generators
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/hmac"
 	"crypto/md5"
+	"crypto/rand"
 	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/base64"
 	"encoding/hex"
+	"fmt"
+	"hash"
 	"html"
+	"io/ioutil"
+	"math"
+	"math/big"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Knetic/govaluate"
+	"github.com/blang/semver"
+	"github.com/projectdiscovery/nuclei/v2/pkg/query"
+)
+
+const (
+	lettersCharset      = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	numbersCharset      = "0123456789"
+	alphanumericCharset = lettersCharset + numbersCharset
 )
 
 // HelperFunctions contains the dsl functions
@@ -38,7 +59,7 @@ func HelperFunctions() (functions map[string]govaluate.ExpressionFunction) {
 	}
 
 	functions["trim"] = func(args ...interface{}) (interface{}, error) {
-		return strings.Trim(args[0].(string), args[2].(string)), nil
+		return strings.Trim(args[0].(string), args[1].(string)), nil
 	}
 
 	functions["trimleft"] = func(args ...interface{}) (interface{}, error) {
@@ -73,9 +94,30 @@ func HelperFunctions() (functions map[string]govaluate.ExpressionFunction) {
 	}
 
 	functions["base64_decode"] = func(args ...interface{}) (interface{}, error) {
+		sDec, err := base64.StdEncoding.DecodeString(args[0].(string))
+		if err != nil {
+			return nil, err
+		}
+
+		return string(sDec), nil
+	}
+
+	// base64_py encodes like python base64.encodebytes, with a line feed every 76
+	// characters and at the end, as used to compute the hashes of favicons.
+	functions["base64_py"] = func(args ...interface{}) (interface{}, error) {
 		sEnc := base64.StdEncoding.EncodeToString([]byte(args[0].(string)))
 
-		return sEnc, nil
+		builder := &strings.Builder{}
+		for len(sEnc) > 76 {
+			builder.WriteString(sEnc[:76])
+			builder.WriteByte('\n')
+			sEnc = sEnc[76:]
+		}
+
+		builder.WriteString(sEnc)
+		builder.WriteByte('\n')
+
+		return builder.String(), nil
 	}
 
 	functions["url_encode"] = func(args ...interface{}) (interface{}, error) {
@@ -86,6 +128,14 @@ func HelperFunctions() (functions map[string]govaluate.ExpressionFunction) {
 		return url.PathUnescape(args[0].(string))
 	}
 
+	functions["url_query_encode"] = func(args ...interface{}) (interface{}, error) {
+		return url.QueryEscape(args[0].(string)), nil
+	}
+
+	functions["url_query_decode"] = func(args ...interface{}) (interface{}, error) {
+		return url.QueryUnescape(args[0].(string))
+	}
+
 	functions["hex_encode"] = func(args ...interface{}) (interface{}, error) {
 		return hex.EncodeToString([]byte(args[0].(string))), nil
 	}
@@ -132,6 +182,248 @@ func HelperFunctions() (functions map[string]govaluate.ExpressionFunction) {
 		return hex.EncodeToString(h.Sum(nil)), nil
 	}
 
+	functions["sha512"] = func(args ...interface{}) (interface{}, error) {
+		hash := sha512.Sum512([]byte(args[0].(string)))
+
+		return hex.EncodeToString(hash[:]), nil
+	}
+
+	// hmac(algorithm, data, key) with md5, sha1, sha256 or sha512
+	functions["hmac"] = func(args ...interface{}) (interface{}, error) {
+		var newHash func() hash.Hash
+
+		switch strings.ToLower(args[0].(string)) {
+		case "md5":
+			newHash = md5.New
+		case "sha1":
+			newHash = sha1.New
+		case "sha256":
+			newHash = sha256.New
+		case "sha512":
+			newHash = sha512.New
+		default:
+			return nil, fmt.Errorf("unknown hmac algorithm: %s", args[0])
+		}
+
+		h := hmac.New(newHash, []byte(args[2].(string)))
+		if _, err := h.Write([]byte(args[1].(string))); err != nil {
+			return nil, err
+		}
+
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	functions["mmh3"] = func(args ...interface{}) (interface{}, error) {
+		return float64(int32(murmur3([]byte(args[0].(string))))), nil
+	}
+
+	// compression
+	functions["gzip_encode"] = func(args ...interface{}) (interface{}, error) {
+		buffer := &bytes.Buffer{}
+		writer := gzip.NewWriter(buffer)
+
+		if _, err := writer.Write([]byte(args[0].(string))); err != nil {
+			return nil, err
+		}
+
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+
+		return buffer.String(), nil
+	}
+
+	functions["gzip_decode"] = func(args ...interface{}) (interface{}, error) {
+		reader, err := gzip.NewReader(strings.NewReader(args[0].(string)))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		return string(data), nil
+	}
+
+	functions["zlib_encode"] = func(args ...interface{}) (interface{}, error) {
+		buffer := &bytes.Buffer{}
+		writer := zlib.NewWriter(buffer)
+
+		if _, err := writer.Write([]byte(args[0].(string))); err != nil {
+			return nil, err
+		}
+
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+
+		return buffer.String(), nil
+	}
+
+	functions["zlib_decode"] = func(args ...interface{}) (interface{}, error) {
+		reader, err := zlib.NewReader(strings.NewReader(args[0].(string)))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		return string(data), nil
+	}
+
+	// random values
+	functions["rand_char"] = func(args ...interface{}) (interface{}, error) {
+		charset := alphanumericCharset
+		if len(args) > 0 {
+			charset = args[0].(string)
+		}
+
+		return randString(charset, 1)
+	}
+
+	functions["rand_text_alpha"] = func(args ...interface{}) (interface{}, error) {
+		return randString(lettersCharset, int(args[0].(float64)))
+	}
+
+	functions["rand_text_alphanumeric"] = func(args ...interface{}) (interface{}, error) {
+		return randString(alphanumericCharset, int(args[0].(float64)))
+	}
+
+	functions["rand_text_numeric"] = func(args ...interface{}) (interface{}, error) {
+		return randString(numbersCharset, int(args[0].(float64)))
+	}
+
+	// rand_int(min, max) returns an integer between min and max included,
+	// by default between 0 and the maximum 32 bits integer.
+	functions["rand_int"] = func(args ...interface{}) (interface{}, error) {
+		min, max := int64(0), int64(math.MaxInt32)
+		if len(args) > 0 {
+			min = int64(args[0].(float64))
+		}
+
+		if len(args) > 1 {
+			max = int64(args[1].(float64))
+		}
+
+		if max < min {
+			return nil, fmt.Errorf("invalid range %d-%d", min, max)
+		}
+
+		n, err := rand.Int(rand.Reader, big.NewInt(max-min+1))
+		if err != nil {
+			return nil, err
+		}
+
+		return float64(min + n.Int64()), nil
+	}
+
+	// time
+	// unix_time(offset) returns the current unix time in seconds, plus an optional offset
+	functions["unix_time"] = func(args ...interface{}) (interface{}, error) {
+		now := float64(time.Now().Unix())
+		if len(args) > 0 {
+			now += args[0].(float64)
+		}
+
+		return now, nil
+	}
+
+	// date(format, time) formats a unix time, or the current time, in utc with
+	// strftime directives like %Y-%m-%d, as date strings are parsed as dates.
+	functions["date"] = func(args ...interface{}) (interface{}, error) {
+		date := time.Now()
+		if len(args) > 1 {
+			date = time.Unix(int64(args[1].(float64)), 0)
+		}
+
+		return formatDate(date.UTC(), args[0].(string)), nil
+	}
+
+	// strings
+	functions["concat"] = func(args ...interface{}) (interface{}, error) {
+		builder := &strings.Builder{}
+		for _, arg := range args {
+			builder.WriteString(toString(arg))
+		}
+
+		return builder.String(), nil
+	}
+
+	functions["repeat"] = func(args ...interface{}) (interface{}, error) {
+		count := int(args[1].(float64))
+		if count < 0 {
+			return nil, fmt.Errorf("negative repeat count %d", count)
+		}
+
+		return strings.Repeat(args[0].(string), count), nil
+	}
+
+	// conversions
+	functions["to_number"] = func(args ...interface{}) (interface{}, error) {
+		if number, ok := args[0].(float64); ok {
+			return number, nil
+		}
+
+		return strconv.ParseFloat(strings.TrimSpace(toString(args[0])), 64)
+	}
+
+	functions["to_string"] = func(args ...interface{}) (interface{}, error) {
+		return toString(args[0]), nil
+	}
+
+	// json_path(json, path) returns the first value selected by a jq-style path like
+	// .data.items[0].name in a json document. Objects and arrays are returned as json.
+	functions["json_path"] = func(args ...interface{}) (interface{}, error) {
+		path := args[1].(string)
+
+		compiled, err := query.ParseJSON(path)
+		if err != nil {
+			return nil, err
+		}
+
+		document, err := query.ParseJSONDocument(args[0].(string))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, value := range compiled.Run(document) {
+			if result, ok := query.JSONValueToString(value); ok {
+				return result, nil
+			}
+		}
+
+		return nil, fmt.Errorf("no value found at json path %s", path)
+	}
+
+	// compare_versions(version, constraints...) checks a version against semver
+	// ranges like ">=1.2.0 <2.0.0", all the ranges having to match.
+	functions["compare_versions"] = func(args ...interface{}) (interface{}, error) {
+		version, err := semver.ParseTolerant(args[0].(string))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, arg := range args[1:] {
+			versionRange, err := semver.ParseRange(arg.(string))
+			if err != nil {
+				return nil, err
+			}
+
+			if !versionRange(version) {
+				return false, nil
+			}
+		}
+
+		return true, nil
+	}
+
 	// search
 	functions["contains"] = func(args ...interface{}) (interface{}, error) {
 		return strings.Contains(args[0].(string), args[1].(string)), nil
@@ -146,5 +438,153 @@ func HelperFunctions() (functions map[string]govaluate.ExpressionFunction) {
 		return compiled.MatchString(args[1].(string)), nil
 	}
 
+	for name, function := range functions {
+		functions[name] = safeFunction(name, function)
+	}
+
 	return functions
 }
+
+// safeFunction returns an error instead of panicking when a function
+// is called with missing arguments or arguments of the wrong type.
+func safeFunction(name string, function govaluate.ExpressionFunction) govaluate.ExpressionFunction {
+	return func(args ...interface{}) (result interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				result, err = nil, fmt.Errorf("invalid arguments for %s: %v", name, r)
+			}
+		}()
+
+		return function(args...)
+	}
+}
+
+// toString converts a value to a string, without decimals for the numbers that are integers
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// dateDirectives contains the go layouts of the strftime directives
+var dateDirectives = map[byte]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'd': "02",
+	'H': "15",
+	'M': "04",
+	'S': "05",
+	'b': "Jan",
+	'B': "January",
+	'a': "Mon",
+	'A': "Monday",
+	'Z': "MST",
+	'z': "-0700",
+}
+
+// formatDate formats a date with strftime directives, the other characters being kept as is
+func formatDate(date time.Time, format string) string {
+	builder := &strings.Builder{}
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i == len(format)-1 {
+			builder.WriteByte(format[i])
+
+			continue
+		}
+
+		i++
+
+		layout, ok := dateDirectives[format[i]]
+
+		switch {
+		case ok:
+			builder.WriteString(date.Format(layout))
+		case format[i] == 's':
+			builder.WriteString(strconv.FormatInt(date.Unix(), 10))
+		case format[i] == '%':
+			builder.WriteByte('%')
+		default:
+			builder.WriteByte('%')
+			builder.WriteByte(format[i])
+		}
+	}
+
+	return builder.String()
+}
+
+// randString returns a random string of the given length made of the characters of the charset
+func randString(charset string, length int) (string, error) {
+	if charset == "" || length < 0 {
+		return "", fmt.Errorf("invalid random string of %d characters from '%s'", length, charset)
+	}
+
+	builder := &strings.Builder{}
+	max := big.NewInt(int64(len(charset)))
+
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+
+		builder.WriteByte(charset[n.Int64()])
+	}
+
+	return builder.String(), nil
+}
+
+// murmur3 returns the 32 bits murmur3 hash of the data with a seed of 0
+func murmur3(data []byte) uint32 {
+	const (
+		c1 = 0xcc9e2d51
+		c2 = 0x1b873593
+	)
+
+	var h uint32
+
+	blocks := len(data) / 4
+	for i := 0; i < blocks; i++ {
+		k := uint32(data[i*4]) | uint32(data[i*4+1])<<8 | uint32(data[i*4+2])<<16 | uint32(data[i*4+3])<<24
+		k *= c1
+		k = k<<15 | k>>17
+		k *= c2
+
+		h ^= k
+		h = h<<13 | h>>19
+		h = h*5 + 0xe6546b64
+	}
+
+	var k uint32
+
+	tail := data[blocks*4:]
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = k<<15 | k>>17
+		k *= c2
+		h ^= k
+	}
+
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+
+	return h
+}
@@ -0,0 +1,142 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Knetic/govaluate"
+	"github.com/stretchr/testify/require"
+)
+
+func evaluate(t *testing.T, expression string) interface{} {
+	return evaluateWithParameters(t, expression, nil)
+}
+
+func evaluateWithParameters(t *testing.T, expression string, parameters map[string]interface{}) interface{} {
+	compiled, err := govaluate.NewEvaluableExpressionWithFunctions(expression, HelperFunctions())
+	require.Nil(t, err, "Could not compile expression %s", expression)
+
+	result, err := compiled.Evaluate(parameters)
+	require.Nil(t, err, "Could not evaluate expression %s", expression)
+
+	return result
+}
+
+func TestTrim(t *testing.T) {
+	require.Equal(t, "abc", evaluate(t, `trim("--abc--", "-")`), "Could not trim string")
+}
+
+func TestBase64Decode(t *testing.T) {
+	require.Equal(t, "admin:pass", evaluate(t, `base64_decode("YWRtaW46cGFzcw==")`), "Could not decode base64")
+
+	_, err := HelperFunctions()["base64_decode"]("%%%")
+	require.NotNil(t, err, "Could decode invalid base64")
+}
+
+func TestBase64Py(t *testing.T) {
+	encoded := evaluate(t, `base64_py("`+strings.Repeat("a", 60)+`")`).(string)
+	lines := strings.Split(encoded, "\n")
+
+	require.Len(t, lines, 3, "Could not split base64 in lines")
+	require.Len(t, lines[0], 76, "Could not split base64 at 76 characters")
+	require.Equal(t, "", lines[2], "Could not end base64 with a line feed")
+}
+
+func TestURLQueryEncode(t *testing.T) {
+	require.Equal(t, "a+b%26c%3Dd", evaluate(t, `url_query_encode("a b&c=d")`), "Could not encode query")
+	require.Equal(t, "a b&c=d", evaluate(t, `url_query_decode("a+b%26c%3Dd")`), "Could not decode query")
+}
+
+func TestSHA512(t *testing.T) {
+	require.Equal(t, "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f", evaluate(t, `sha512("abc")`), "Could not hash with sha512")
+}
+
+func TestHMAC(t *testing.T) {
+	message := `"The quick brown fox jumps over the lazy dog"`
+
+	require.Equal(t, "80070713463e7749b90c2dc24911e275", evaluate(t, `hmac("md5", `+message+`, "key")`), "Could not compute hmac md5")
+	require.Equal(t, "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8", evaluate(t, `hmac("sha256", `+message+`, "key")`), "Could not compute hmac sha256")
+
+	_, err := HelperFunctions()["hmac"]("unknown", "data", "key")
+	require.NotNil(t, err, "Could compute hmac with unknown algorithm")
+}
+
+func TestMMH3(t *testing.T) {
+	require.Equal(t, float64(0), evaluate(t, `mmh3("")`), "Could not hash empty string")
+	require.Equal(t, float64(613153351), evaluate(t, `mmh3("hello")`), "Could not hash with mmh3")
+	require.Equal(t, float64(-156908512), evaluate(t, `mmh3("foo")`), "Could not hash with negative mmh3")
+}
+
+func TestCompression(t *testing.T) {
+	require.Equal(t, "data", evaluate(t, `gzip_decode(gzip_encode("data"))`), "Could not round trip gzip")
+	require.Equal(t, "data", evaluate(t, `zlib_decode(zlib_encode("data"))`), "Could not round trip zlib")
+
+	_, err := HelperFunctions()["gzip_decode"]("data")
+	require.NotNil(t, err, "Could decode invalid gzip")
+}
+
+func TestRandom(t *testing.T) {
+	char := evaluate(t, `rand_char("x")`)
+	require.Equal(t, "x", char, "Could not get random char from charset")
+
+	alpha := evaluate(t, `rand_text_alpha(10)`).(string)
+	require.Len(t, alpha, 10, "Could not get random text of length")
+	require.Equal(t, -1, strings.IndexAny(alpha, numbersCharset), "Could get digits in alpha text")
+
+	numeric := evaluate(t, `rand_text_numeric(5)`).(string)
+	require.Equal(t, "", strings.Trim(numeric, numbersCharset), "Could get letters in numeric text")
+
+	for i := 0; i < 20; i++ {
+		number := evaluate(t, `rand_int(1, 3)`).(float64)
+		require.True(t, number >= 1 && number <= 3, "Could get random int out of range")
+	}
+}
+
+func TestTime(t *testing.T) {
+	now := float64(time.Now().Unix())
+
+	require.InDelta(t, now, evaluate(t, `unix_time()`), 5, "Could not get unix time")
+	require.InDelta(t, now+60, evaluate(t, `unix_time(60)`), 5, "Could not get unix time with offset")
+	require.Equal(t, "1970-01-02 00:00:00 %", evaluate(t, `date("%Y-%m-%d %H:%M:%S %%", 86400)`), "Could not format date")
+}
+
+func TestConcatRepeat(t *testing.T) {
+	require.Equal(t, "a1b", evaluate(t, `concat("a", 1, "b")`), "Could not concat values")
+	require.Equal(t, "ababab", evaluate(t, `repeat("ab", 3)`), "Could not repeat string")
+}
+
+func TestConversions(t *testing.T) {
+	require.Equal(t, float64(42), evaluate(t, `to_number("42")`), "Could not convert string to number")
+	require.Equal(t, "42", evaluate(t, `to_string(42)`), "Could not convert number to string")
+	require.Equal(t, true, evaluate(t, `to_number("1.5") + 1 == 2.5`), "Could not compute with converted number")
+}
+
+func TestJSONPath(t *testing.T) {
+	parameters := map[string]interface{}{"body": `{"a": {"b": [1, {"c": "d"}]}}`}
+
+	require.Equal(t, "d", evaluateWithParameters(t, `json_path(body, ".a.b[1].c")`, parameters), "Could not get json string")
+	require.Equal(t, "1", evaluateWithParameters(t, `json_path(body, "a.b[0]")`, parameters), "Could not get json number")
+	require.Equal(t, `{"b":[1,{"c":"d"}]}`, evaluateWithParameters(t, `json_path(body, ".a")`, parameters), "Could not get json object")
+	require.Equal(t, "d", evaluateWithParameters(t, `json_path(body, ".a.b[].c")`, parameters), "Could not get first iterated json value")
+
+	_, err := HelperFunctions()["json_path"](`{"a": 1}`, ".b")
+	require.NotNil(t, err, "Could get missing json key")
+
+	_, err = HelperFunctions()["json_path"](`{"a": 1}`, ".a..b")
+	require.NotNil(t, err, "Could use invalid json path")
+}
+
+func TestCompareVersions(t *testing.T) {
+	require.Equal(t, true, evaluate(t, `compare_versions("1.2.3", ">=1.0.0 <2.0.0")`), "Could not match version range")
+	require.Equal(t, false, evaluate(t, `compare_versions("v2.1", ">=1.0.0 <2.0.0")`), "Could match version out of range")
+	require.Equal(t, false, evaluate(t, `compare_versions("1.5.0", ">=1.0.0", "<1.5.0")`), "Could match version out of one range")
+}
+
+func TestInvalidArguments(t *testing.T) {
+	_, err := HelperFunctions()["base64"](1.0)
+	require.NotNil(t, err, "Could call function with invalid argument type")
+
+	_, err = HelperFunctions()["repeat"]("a")
+	require.NotNil(t, err, "Could call function with missing argument")
+}
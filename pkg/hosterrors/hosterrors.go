@@ -0,0 +1,130 @@
+// Package hosterrors tracks the connection errors of the hosts to skip
+// the ones which stopped responding during a scan.
+package hosterrors
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Cache counts the consecutive connection errors of each host and marks a host
+// as dead once it reaches the maximum number of errors. A nil cache never marks
+// any host as dead. It is safe for concurrent use.
+type Cache struct {
+	maxErrors int
+	errors    map[string]int
+	dead      map[string]struct{}
+	mutex     sync.Mutex
+}
+
+// New creates a cache marking hosts as dead after maxErrors consecutive
+// connection errors. It returns nil if maxErrors is 0 or less.
+func New(maxErrors int) *Cache {
+	if maxErrors <= 0 {
+		return nil
+	}
+
+	return &Cache{maxErrors: maxErrors, errors: make(map[string]int), dead: make(map[string]struct{})}
+}
+
+// Key returns the host of a target, used as the key of the cache. The keys of urls
+// are their host and port, like example.com:443, so that each service of a host is
+// tracked separately. The errors of the authoritative nameservers of a domain are
+// tracked under the bare domain name, which is never the key of a url.
+func Key(target string) string {
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Host == "" {
+		return target
+	}
+
+	if parsed.Port() != "" {
+		return parsed.Host
+	}
+
+	switch parsed.Scheme {
+	case "https", "wss":
+		return net.JoinHostPort(parsed.Hostname(), "443")
+	case "http", "ws":
+		return net.JoinHostPort(parsed.Hostname(), "80")
+	}
+
+	return parsed.Host
+}
+
+// IsDead returns whether the host was marked as dead
+func (c *Cache) IsDead(host string) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	_, ok := c.dead[host]
+
+	return ok
+}
+
+// MarkFailed records an error for the host. Only connection errors and timeouts
+// are counted, other errors mean the host is still responding.
+func (c *Cache) MarkFailed(host string, err error) {
+	if c == nil || !isConnectionError(err) {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.errors[host]++
+
+	if c.errors[host] >= c.maxErrors {
+		c.dead[host] = struct{}{}
+	}
+}
+
+// MarkSuccess records that the host responded, resetting its error count
+func (c *Cache) MarkSuccess(host string) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.errors, host)
+}
+
+// DeadHosts returns the sorted list of the hosts marked as dead
+func (c *Cache) DeadHosts() []string {
+	if c == nil {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	hosts := make([]string, 0, len(c.dead))
+	for host := range c.dead {
+		hosts = append(hosts, host)
+	}
+
+	sort.Strings(hosts)
+
+	return hosts
+}
+
+// isConnectionError returns whether the error is a timeout or a failure to reach the host
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var opErr *net.OpError
+
+	return errors.As(err, &opErr)
+}
@@ -0,0 +1,22 @@
+package hosterrors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKey(t *testing.T) {
+	tests := map[string]string{
+		"http://example.com/admin":   "example.com:80",
+		"https://example.com":        "example.com:443",
+		"https://example.com:8443/x": "example.com:8443",
+		"ws://[::1]/socket":          "[::1]:80",
+		"example.com":                "example.com",
+		"ftp://example.com/file":     "example.com",
+	}
+
+	for target, expected := range tests {
+		require.Equal(t, expected, Key(target), "Could not get key of %s", target)
+	}
+}
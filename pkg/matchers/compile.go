@@ -6,11 +6,15 @@ import (
 
 	"github.com/Knetic/govaluate"
 	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/query"
 )
 
 // CompileMatchers performs the initial setup operation on a matcher
 func (m *Matcher) CompileMatchers() error {
-	var ok bool
+	var (
+		ok  bool
+		err error
+	)
 
 	// Setup the matcher type
 	m.matcherType, ok = MatcherTypes[m.Type]
@@ -38,6 +42,44 @@ func (m *Matcher) CompileMatchers() error {
 		m.dslCompiled = append(m.dslCompiled, compiled)
 	}
 
+	// Compile the json queries
+	for _, jsonQuery := range m.JSON {
+		compiled, err := query.ParseJSON(jsonQuery)
+		if err != nil {
+			return fmt.Errorf("could not compile json query: %s", jsonQuery)
+		}
+
+		m.jsonCompiled = append(m.jsonCompiled, compiled)
+	}
+
+	// Compile the xpath queries
+	for _, xpath := range m.XPath {
+		compiled, err := query.ParseXPath(xpath)
+		if err != nil {
+			return fmt.Errorf("could not compile xpath: %s", xpath)
+		}
+
+		m.xpathCompiled = append(m.xpathCompiled, compiled)
+	}
+
+	// Parse the dns response codes
+	m.rcodes, err = parseRCodes(m.RCode)
+	if err != nil {
+		return err
+	}
+
+	// Parse the ttl ranges
+	m.ttlRanges, err = parseTTLRanges(m.TTL)
+	if err != nil {
+		return err
+	}
+
+	// Parse the dns record types
+	m.recordTypes, err = ParseDNSRecordTypes(m.RecordTypes)
+	if err != nil {
+		return err
+	}
+
 	// Setup the condition type, if any.
 	if m.Condition != "" {
 		m.condition, ok = ConditionTypes[m.Condition]
@@ -60,3 +102,15 @@ func (m *Matcher) CompileMatchers() error {
 
 	return nil
 }
+
+// ValidateProtocol returns an error if the type of a compiled matcher
+// can't be matched by the requests of a protocol.
+func (m *Matcher) ValidateProtocol(protocol string) error {
+	for _, matcherType := range ProtocolMatcherTypes[protocol] {
+		if m.matcherType == matcherType {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("matcher type %s is not supported by %s requests", m.Type, protocol)
+}
@@ -0,0 +1,255 @@
+package matchers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// DNSSection is a section of the records of a dns response
+type DNSSection int
+
+const (
+	// AllSections selects the records of all the sections
+	AllSections DNSSection = iota
+	// AnswerSection selects the records of the answer section
+	AnswerSection
+	// AuthoritySection selects the records of the authority section
+	AuthoritySection
+	// AdditionalSection selects the records of the additional section
+	AdditionalSection
+)
+
+// DNSRecords returns the records of a section of a dns response. Only the records
+// of the given types are returned, if any. EDNS pseudo records are never returned.
+func DNSRecords(msg *dns.Msg, section DNSSection, types []uint16) []dns.RR {
+	var sections [][]dns.RR
+
+	switch section {
+	case AnswerSection:
+		sections = [][]dns.RR{msg.Answer}
+	case AuthoritySection:
+		sections = [][]dns.RR{msg.Ns}
+	case AdditionalSection:
+		sections = [][]dns.RR{msg.Extra}
+	default:
+		sections = [][]dns.RR{msg.Answer, msg.Ns, msg.Extra}
+	}
+
+	var records []dns.RR
+
+	for _, section := range sections {
+		for _, record := range section {
+			if record.Header().Rrtype == dns.TypeOPT || !hasRecordType(types, record.Header().Rrtype) {
+				continue
+			}
+
+			records = append(records, record)
+		}
+	}
+
+	return records
+}
+
+// hasRecordType returns true if the record type is one of the types, or if there are no types
+func hasRecordType(types []uint16, recordType uint16) bool {
+	if len(types) == 0 {
+		return true
+	}
+
+	for _, t := range types {
+		if t == recordType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DNSCorpus returns the text of the records of a section of a dns response. The whole
+// response is returned if all the sections and all the record types are selected.
+func DNSCorpus(msg *dns.Msg, section DNSSection, types []uint16) string {
+	if section == AllSections && len(types) == 0 {
+		return msg.String()
+	}
+
+	builder := &strings.Builder{}
+
+	for _, record := range DNSRecords(msg, section, types) {
+		builder.WriteString(record.String())
+		builder.WriteRune('\n')
+	}
+
+	return builder.String()
+}
+
+// DNSRecordValue returns the data of a record without its header, e.g. the
+// target of a CNAME record or the address of an A record. The strings of
+// TXT records are joined without quotes.
+func DNSRecordValue(record dns.RR) string {
+	if txt, ok := record.(*dns.TXT); ok {
+		return strings.Join(txt.Txt, "")
+	}
+
+	return strings.TrimPrefix(record.String(), record.Header().String())
+}
+
+// ParseDNSRecordTypes converts record type names like CNAME to their values
+func ParseDNSRecordTypes(names []string) ([]uint16, error) {
+	types := make([]uint16, 0, len(names))
+
+	for _, name := range names {
+		recordType, ok := dns.StringToType[strings.ToUpper(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("unknown dns record type: %s", name)
+		}
+
+		types = append(types, recordType)
+	}
+
+	return types, nil
+}
+
+// parseRCodes converts response code names like NXDOMAIN, or their values, to values
+func parseRCodes(names []string) ([]int, error) {
+	rcodes := make([]int, 0, len(names))
+
+	for _, name := range names {
+		name = strings.ToUpper(strings.TrimSpace(name))
+
+		if rcode, ok := dns.StringToRcode[name]; ok {
+			rcodes = append(rcodes, rcode)
+			continue
+		}
+
+		rcode, err := strconv.Atoi(name)
+		if err != nil {
+			return nil, fmt.Errorf("unknown dns response code: %s", name)
+		}
+
+		rcodes = append(rcodes, rcode)
+	}
+
+	return rcodes, nil
+}
+
+// ttlRange is an inclusive range of ttl values
+type ttlRange struct {
+	min, max int64
+}
+
+// parseTTLRanges parses ttl ranges written as 300, 60-300, <60, <=60, >3600 or >=3600
+func parseTTLRanges(ranges []string) ([]ttlRange, error) {
+	parsed := make([]ttlRange, 0, len(ranges))
+
+	for _, value := range ranges {
+		value = strings.ReplaceAll(value, " ", "")
+
+		var (
+			r   ttlRange
+			err error
+		)
+
+		switch {
+		case strings.HasPrefix(value, "<="):
+			r.max, err = strconv.ParseInt(value[2:], 10, 64)
+		case strings.HasPrefix(value, "<"):
+			r.max, err = strconv.ParseInt(value[1:], 10, 64)
+			r.max--
+		case strings.HasPrefix(value, ">="):
+			r.min, err = strconv.ParseInt(value[2:], 10, 64)
+			r.max = math.MaxInt64
+		case strings.HasPrefix(value, ">"):
+			r.min, err = strconv.ParseInt(value[1:], 10, 64)
+			r.min++
+			r.max = math.MaxInt64
+		case strings.Contains(value, "-"):
+			parts := strings.SplitN(value, "-", 2)
+
+			r.min, err = strconv.ParseInt(parts[0], 10, 64)
+			if err == nil {
+				r.max, err = strconv.ParseInt(parts[1], 10, 64)
+			}
+		default:
+			r.min, err = strconv.ParseInt(value, 10, 64)
+			r.max = r.min
+		}
+
+		if err != nil || r.min > r.max {
+			return nil, fmt.Errorf("invalid ttl range: %s", value)
+		}
+
+		parsed = append(parsed, r)
+	}
+
+	return parsed, nil
+}
+
+// dnsSection returns the section of a dns response the part of the matcher selects
+func (m *Matcher) dnsSection() DNSSection {
+	switch m.part {
+	case AnswerPart:
+		return AnswerSection
+	case AuthorityPart:
+		return AuthoritySection
+	case AdditionalPart:
+		return AdditionalSection
+	}
+
+	return AllSections
+}
+
+// matchRCode matches the response code of a dns response
+func (m *Matcher) matchRCode(rcode int) bool {
+	// Iterate over all the response codes accepted as valid
+	//
+	// Response codes don't support AND conditions.
+	for _, value := range m.rcodes {
+		if rcode == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchTTL matches the ttl ranges against the ttl of dns records. A range
+// matches if the ttl of any of the records is within the range.
+func (m *Matcher) matchTTL(records []dns.RR) bool {
+	// Iterate over all the ranges accepted as valid
+	for i, r := range m.ttlRanges {
+		matched := false
+
+		for _, record := range records {
+			if ttl := int64(record.Header().Ttl); ttl >= r.min && ttl <= r.max {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			// If we are in an AND request and a match failed,
+			// return false as the AND condition fails on any single mismatch.
+			if m.condition == ANDCondition {
+				return false
+			}
+			// Continue with the flow since its an OR Condition.
+			continue
+		}
+
+		// If the condition was an OR, return on the first match.
+		if m.condition == ORCondition {
+			return true
+		}
+
+		// If we are at the end of the ranges, return with true
+		if len(m.ttlRanges)-1 == i {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,10 +2,13 @@ package matchers
 
 import (
 	"encoding/hex"
+	"mime"
 	"net/http"
+	"path/filepath"
 	"strings"
 
 	"github.com/miekg/dns"
+	"github.com/projectdiscovery/nuclei/v2/pkg/query"
 )
 
 // Match matches a http response again a given matcher
@@ -44,30 +47,149 @@ func (m *Matcher) Match(resp *http.Response, body, headers string) bool {
 		}
 	case DSLMatcher:
 		// Match complex query
-		return m.isNegative(m.matchDSL(httpToMap(resp, body, headers)))
+		return m.isNegative(m.matchDSL(HTTPToMap(resp, body, headers)))
+	case JSONMatcher:
+		return m.isNegative(m.matchJSON(body))
+	case XPathMatcher:
+		return m.isNegative(m.matchXPath(body, ContentType(resp)))
 	}
 
 	return false
 }
 
-// MatchDNS matches a dns response against a given matcher
+// MatchDNS matches a dns response against a given matcher. Words, regexes, binary
+// data and ttl ranges are matched against the records of the part and types selected
+// by the matcher, or against the whole response by default.
 func (m *Matcher) MatchDNS(msg *dns.Msg) bool {
+	if msg == nil {
+		return false
+	}
+
+	switch m.matcherType {
+	case RCodeMatcher:
+		return m.isNegative(m.matchRCode(msg.Rcode))
+	case TTLMatcher:
+		return m.isNegative(m.matchTTL(DNSRecords(msg, m.dnsSection(), m.recordTypes)))
+	case SizeMatcher:
+		return m.isNegative(m.matchSizeCode(msg.Len()))
+	case WordsMatcher:
+		// Match for word check
+		return m.isNegative(m.matchWords(DNSCorpus(msg, m.dnsSection(), m.recordTypes)))
+	case RegexMatcher:
+		// Match regex check
+		return m.isNegative(m.matchRegex(DNSCorpus(msg, m.dnsSection(), m.recordTypes)))
+	case BinaryMatcher:
+		// Match binary characters check
+		return m.isNegative(m.matchBinary(DNSCorpus(msg, m.dnsSection(), m.recordTypes)))
+	case DSLMatcher:
+		// Match complex query
+		return m.isNegative(m.matchDSL(DNSToMap(msg)))
+	}
+
+	return false
+}
+
+// MatchNetwork matches data received from a network connection against a given matcher
+func (m *Matcher) MatchNetwork(data string) bool {
+	switch m.matcherType {
+	case SizeMatcher:
+		return m.isNegative(m.matchSizeCode(len(data)))
+	case WordsMatcher:
+		// Match for word check
+		return m.isNegative(m.matchWords(data))
+	case RegexMatcher:
+		// Match regex check
+		return m.isNegative(m.matchRegex(data))
+	case BinaryMatcher:
+		// Match binary characters check
+		return m.isNegative(m.matchBinary(data))
+	case DSLMatcher:
+		// Match complex query
+		return m.isNegative(m.matchDSL(NetworkToMap(data)))
+	case JSONMatcher:
+		return m.isNegative(m.matchJSON(data))
+	case XPathMatcher:
+		return m.isNegative(m.matchXPath(data, ""))
+	}
+
+	return false
+}
+
+// MatchFile matches the content of a file against a given matcher
+func (m *Matcher) MatchFile(path, data string) bool {
 	switch m.matcherType {
-	// [WIP] add dns status code matcher
 	case SizeMatcher:
-		return m.matchSizeCode(msg.Len())
+		return m.isNegative(m.matchSizeCode(len(data)))
 	case WordsMatcher:
 		// Match for word check
-		return m.matchWords(msg.String())
+		return m.isNegative(m.matchWords(data))
 	case RegexMatcher:
 		// Match regex check
-		return m.matchRegex(msg.String())
+		return m.isNegative(m.matchRegex(data))
 	case BinaryMatcher:
 		// Match binary characters check
-		return m.matchBinary(msg.String())
+		return m.isNegative(m.matchBinary(data))
+	case DSLMatcher:
+		// Match complex query
+		return m.isNegative(m.matchDSL(FileToMap(path, data)))
+	case JSONMatcher:
+		return m.isNegative(m.matchJSON(data))
+	case XPathMatcher:
+		return m.isNegative(m.matchXPath(data, mime.TypeByExtension(filepath.Ext(path))))
+	}
+
+	return false
+}
+
+// Locate returns the offsets in the corpus of the first occurrence of each
+// word, regex or binary data of the matcher. Other matcher types, as well as
+// negative matchers, have no location.
+func (m *Matcher) Locate(corpus string) []int {
+	var offsets []int
+
+	if m.Negative {
+		return offsets
+	}
+
+	switch m.matcherType {
+	case WordsMatcher:
+		for _, word := range m.Words {
+			if index := strings.Index(corpus, word); index >= 0 {
+				offsets = append(offsets, index)
+			}
+		}
+	case RegexMatcher:
+		for _, regex := range m.regexCompiled {
+			if loc := regex.FindStringIndex(corpus); loc != nil {
+				offsets = append(offsets, loc[0])
+			}
+		}
+	case BinaryMatcher:
+		for _, binary := range m.Binary {
+			hexa, _ := hex.DecodeString(binary)
+			if index := strings.Index(corpus, string(hexa)); index >= 0 {
+				offsets = append(offsets, index)
+			}
+		}
+	}
+
+	return offsets
+}
+
+// MatchSSL matches the details of a tls handshake against a given matcher
+func (m *Matcher) MatchSSL(data map[string]interface{}) bool {
+	raw, _ := data["raw"].(string)
+
+	switch m.matcherType {
+	case WordsMatcher:
+		// Match for word check
+		return m.isNegative(m.matchWords(raw))
+	case RegexMatcher:
+		// Match regex check
+		return m.isNegative(m.matchRegex(raw))
 	case DSLMatcher:
 		// Match complex query
-		return m.matchDSL(dnsToMap(msg))
+		return m.isNegative(m.matchDSL(data))
 	}
 
 	return false
@@ -231,3 +353,80 @@ func (m *Matcher) matchDSL(mp map[string]interface{}) bool {
 
 	return false
 }
+
+// matchJSON matches the json queries against a json document. A query
+// matches if it selects any value other than null or false.
+func (m *Matcher) matchJSON(corpus string) bool {
+	document, err := query.ParseJSONDocument(corpus)
+	if err != nil {
+		return false
+	}
+
+	// Iterate over all the queries accepted as valid
+	for i, jsonQuery := range m.jsonCompiled {
+		matched := false
+
+		for _, value := range jsonQuery.Run(document) {
+			if value != nil && value != false {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			// If we are in an AND request and a match failed,
+			// return false as the AND condition fails on any single mismatch.
+			if m.condition == ANDCondition {
+				return false
+			}
+			// Continue with the flow since its an OR Condition.
+			continue
+		}
+
+		// If the condition was an OR, return on the first match.
+		if m.condition == ORCondition {
+			return true
+		}
+
+		// If we are at the end of the queries, return with true
+		if len(m.jsonCompiled)-1 == i {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchXPath matches the xpath queries against a html or xml document of
+// the given content type, if known. A query matches if it selects any node.
+func (m *Matcher) matchXPath(corpus, contentType string) bool {
+	document, err := query.ParseDocument(corpus, contentType)
+	if err != nil {
+		return false
+	}
+
+	// Iterate over all the queries accepted as valid
+	for i, xpath := range m.xpathCompiled {
+		if len(xpath.Select(document)) == 0 {
+			// If we are in an AND request and a match failed,
+			// return false as the AND condition fails on any single mismatch.
+			if m.condition == ANDCondition {
+				return false
+			}
+			// Continue with the flow since its an OR Condition.
+			continue
+		}
+
+		// If the condition was an OR, return on the first match.
+		if m.condition == ORCondition {
+			return true
+		}
+
+		// If we are at the end of the queries, return with true
+		if len(m.xpathCompiled)-1 == i {
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,6 +3,7 @@ package matchers
 import (
 	"testing"
 
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/require"
 )
 
@@ -28,3 +29,152 @@ func TestORCondition(t *testing.T) {
 	matched = m.matchWords("c")
 	require.False(t, matched, "Could match invalid OR condition")
 }
+
+func TestLocate(t *testing.T) {
+	m := &Matcher{matcherType: WordsMatcher, Words: []string{"secret", "missing"}}
+
+	offsets := m.Locate("line\nsecret=1")
+	require.Equal(t, []int{5}, offsets, "Could not locate matched word")
+
+	m = &Matcher{matcherType: WordsMatcher, Words: []string{"secret"}, Negative: true}
+
+	offsets = m.Locate("line\nsecret=1")
+	require.Empty(t, offsets, "Could locate negative matcher")
+}
+
+func TestJSONMatcher(t *testing.T) {
+	m := &Matcher{Type: "json", Condition: "and", JSON: []string{".data.admin", ".data.users[].name"}}
+	err := m.CompileMatchers()
+	require.Nil(t, err, "Could not compile json matcher")
+
+	matched := m.MatchNetwork(`{"data": {"admin": true, "users": [{"name": "root"}]}}`)
+	require.True(t, matched, "Could not match valid json")
+
+	matched = m.MatchNetwork(`{"data": {"admin": false, "users": [{"name": "root"}]}}`)
+	require.False(t, matched, "Could match false json value")
+
+	matched = m.MatchNetwork(`not json`)
+	require.False(t, matched, "Could match invalid json")
+}
+
+func TestXPathMatcher(t *testing.T) {
+	m := &Matcher{Type: "xpath", XPath: []string{"//form[@action='/login']//input[@type='password']"}}
+	err := m.CompileMatchers()
+	require.Nil(t, err, "Could not compile xpath matcher")
+
+	matched := m.MatchNetwork(`<html><body><form action="/login"><div><input type="password" name="p"></div></form></body></html>`)
+	require.True(t, matched, "Could not match valid html")
+
+	matched = m.MatchNetwork(`<html><body><form action="/search"><input type="password"></form></body></html>`)
+	require.False(t, matched, "Could match invalid html")
+
+	m = &Matcher{Type: "xpath", XPath: []string{"//form["}}
+	err = m.CompileMatchers()
+	require.NotNil(t, err, "Could compile invalid xpath")
+}
+
+func newDNSResponse(t *testing.T, rcode int, answers ...string) *dns.Msg {
+	msg := &dns.Msg{}
+	msg.SetQuestion("www.example.com.", dns.TypeA)
+	msg.Rcode = rcode
+
+	for _, answer := range answers {
+		record, err := dns.NewRR(answer)
+		require.Nil(t, err, "Could not parse dns record")
+
+		msg.Answer = append(msg.Answer, record)
+	}
+
+	return msg
+}
+
+func TestDNSMatchers(t *testing.T) {
+	msg := newDNSResponse(t, dns.RcodeSuccess, "www.example.com. 300 IN CNAME app.herokudns.com.", "app.herokudns.com. 30 IN A 10.0.0.1")
+
+	m := &Matcher{Type: "rcode", RCode: []string{"nxdomain", "SERVFAIL"}}
+	require.Nil(t, m.CompileMatchers(), "Could not compile rcode matcher")
+	require.False(t, m.MatchDNS(msg), "Could match invalid rcode")
+	require.True(t, m.MatchDNS(newDNSResponse(t, dns.RcodeNameError)), "Could not match valid rcode")
+
+	m = &Matcher{Type: "word", Part: "answer", RecordTypes: []string{"cname"}, Words: []string{"herokudns.com"}}
+	require.Nil(t, m.CompileMatchers(), "Could not compile word matcher")
+	require.True(t, m.MatchDNS(msg), "Could not match valid cname record")
+
+	m = &Matcher{Type: "word", Part: "authority", Words: []string{"herokudns.com"}}
+	require.Nil(t, m.CompileMatchers(), "Could not compile word matcher")
+	require.False(t, m.MatchDNS(msg), "Could match word in empty section")
+
+	m = &Matcher{Type: "ttl", RecordTypes: []string{"A"}, TTL: []string{"<60"}}
+	require.Nil(t, m.CompileMatchers(), "Could not compile ttl matcher")
+	require.True(t, m.MatchDNS(msg), "Could not match valid ttl")
+
+	m = &Matcher{Type: "ttl", RecordTypes: []string{"CNAME"}, TTL: []string{"0-60", ">=3600"}}
+	require.Nil(t, m.CompileMatchers(), "Could not compile ttl matcher")
+	require.False(t, m.MatchDNS(msg), "Could match invalid ttl")
+
+	m = &Matcher{Type: "rcode", RCode: []string{"NOTACODE"}}
+	require.NotNil(t, m.CompileMatchers(), "Could compile invalid rcode")
+
+	m = &Matcher{Type: "word", RecordTypes: []string{"NOTATYPE"}}
+	require.NotNil(t, m.CompileMatchers(), "Could compile invalid record type")
+
+	m = &Matcher{Type: "ttl", TTL: []string{"60-0"}}
+	require.NotNil(t, m.CompileMatchers(), "Could compile invalid ttl range")
+}
+
+func TestDNSRecordValue(t *testing.T) {
+	msg := newDNSResponse(t, dns.RcodeSuccess, "www.example.com. 300 IN CNAME app.herokudns.com.", `www.example.com. 300 IN TXT "v=spf1 " "-all"`)
+
+	var values []string
+	for _, record := range DNSRecords(msg, AnswerSection, nil) {
+		values = append(values, DNSRecordValue(record))
+	}
+
+	require.Equal(t, []string{"app.herokudns.com.", "v=spf1 -all"}, values, "Could not get record values")
+}
+
+func TestDNSMatchersNilResponse(t *testing.T) {
+	for _, m := range []*Matcher{
+		{Type: "rcode", RCode: []string{"NXDOMAIN"}},
+		{Type: "ttl", TTL: []string{"<60"}},
+		{Type: "word", Part: "answer", Words: []string{"a"}},
+	} {
+		require.Nil(t, m.CompileMatchers(), "Could not compile matcher")
+		require.False(t, m.MatchDNS(nil), "Could match nil dns response")
+	}
+}
+
+func TestValidateProtocol(t *testing.T) {
+	tests := []struct {
+		matcherType string
+		protocol    string
+		valid       bool
+	}{
+		{"status", "http", true},
+		{"json", "websocket", true},
+		{"rcode", "http", false},
+		{"ttl", "network", false},
+		{"rcode", "file", false},
+		{"rcode", "dns", true},
+		{"json", "dns", false},
+		{"xpath", "dns", false},
+		{"status", "dns", false},
+		{"xpath", "file", true},
+		{"dsl", "ssl", true},
+		{"size", "ssl", false},
+		{"word", "unknown", false},
+	}
+
+	for _, test := range tests {
+		m := &Matcher{Type: test.matcherType}
+		err := m.CompileMatchers()
+		require.Nil(t, err, "Could not compile %s matcher", test.matcherType)
+
+		err = m.ValidateProtocol(test.protocol)
+		if test.valid {
+			require.Nil(t, err, "Could not validate %s matcher for %s requests", test.matcherType, test.protocol)
+		} else {
+			require.NotNil(t, err, "Could validate %s matcher for %s requests", test.matcherType, test.protocol)
+		}
+	}
+}
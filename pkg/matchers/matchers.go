@@ -4,6 +4,7 @@ import (
 	"regexp"
 
 	"github.com/Knetic/govaluate"
+	"github.com/projectdiscovery/nuclei/v2/pkg/query"
 )
 
 // Matcher is used to identify whether a template was successful.
@@ -31,6 +32,29 @@ type Matcher struct {
 	DSL []string `yaml:"dsl,omitempty"`
 	// dslCompiled is the compiled variant
 	dslCompiled []*govaluate.EvaluableExpression
+	// JSON are the jq-style queries which must select a value other than null or false
+	JSON []string `yaml:"json,omitempty"`
+	// jsonCompiled is the compiled variant
+	jsonCompiled []*query.JSONQuery
+	// XPath are the xpath queries which must select nodes in the html or xml response
+	XPath []string `yaml:"xpath,omitempty"`
+	// xpathCompiled is the compiled variant
+	xpathCompiled []*query.XPath
+	// RCode are the acceptable response codes for dns responses, e.g. NXDOMAIN
+	RCode []string `yaml:"rcode,omitempty"`
+	// rcodes is the compiled variant
+	rcodes []int
+	// TTL are the acceptable ttl ranges for dns records, e.g. 300, 0-60 or >3600
+	TTL []string `yaml:"ttl,omitempty"`
+	// ttlRanges is the compiled variant
+	ttlRanges []ttlRange
+
+	// RecordTypes are the types of the dns records to match, e.g. CNAME
+	//
+	// By default, all the records are matched.
+	RecordTypes []string `yaml:"record-types,omitempty"`
+	// recordTypes is the compiled variant
+	recordTypes []uint16
 
 	// Condition is the optional condition between two matcher variables
 	//
@@ -49,6 +73,10 @@ type Matcher struct {
 	// Negative specifies if the match should be reversed
 	// It will only match if the condition is not true.
 	Negative bool `yaml:"negative,omitempty"`
+
+	// MinMatches is the minimum number of responses the matcher must match
+	// for the requests sent in race mode. Default is 1.
+	MinMatches int `yaml:"min-matches,omitempty"`
 }
 
 // MatcherType is the type of the matcher specified
@@ -67,6 +95,14 @@ const (
 	SizeMatcher
 	// DSLMatcher matches based upon dsl syntax
 	DSLMatcher
+	// JSONMatcher matches responses with jq-style queries
+	JSONMatcher
+	// XPathMatcher matches responses with xpath queries
+	XPathMatcher
+	// RCodeMatcher matches dns responses with response codes
+	RCodeMatcher
+	// TTLMatcher matches dns responses with ttl ranges
+	TTLMatcher
 )
 
 // MatcherTypes is an table for conversion of matcher type from string.
@@ -77,6 +113,20 @@ var MatcherTypes = map[string]MatcherType{
 	"regex":  RegexMatcher,
 	"binary": BinaryMatcher,
 	"dsl":    DSLMatcher,
+	"json":   JSONMatcher,
+	"xpath":  XPathMatcher,
+	"rcode":  RCodeMatcher,
+	"ttl":    TTLMatcher,
+}
+
+// ProtocolMatcherTypes is a table of the matcher types supported by the requests of each protocol.
+var ProtocolMatcherTypes = map[string][]MatcherType{
+	"http":      {StatusMatcher, SizeMatcher, WordsMatcher, RegexMatcher, BinaryMatcher, DSLMatcher, JSONMatcher, XPathMatcher},
+	"websocket": {StatusMatcher, SizeMatcher, WordsMatcher, RegexMatcher, BinaryMatcher, DSLMatcher, JSONMatcher, XPathMatcher},
+	"dns":       {RCodeMatcher, TTLMatcher, SizeMatcher, WordsMatcher, RegexMatcher, BinaryMatcher, DSLMatcher},
+	"network":   {SizeMatcher, WordsMatcher, RegexMatcher, BinaryMatcher, DSLMatcher, JSONMatcher, XPathMatcher},
+	"file":      {SizeMatcher, WordsMatcher, RegexMatcher, BinaryMatcher, DSLMatcher, JSONMatcher, XPathMatcher},
+	"ssl":       {WordsMatcher, RegexMatcher, DSLMatcher},
 }
 
 // ConditionType is the type of condition for matcher
@@ -105,13 +155,22 @@ const (
 	HeaderPart
 	// AllPart matches both response body and headers of the response.
 	AllPart
+	// AnswerPart matches the answer section of a dns response.
+	AnswerPart
+	// AuthorityPart matches the authority section of a dns response.
+	AuthorityPart
+	// AdditionalPart matches the additional section of a dns response.
+	AdditionalPart
 )
 
 // PartTypes is an table for conversion of part type from string.
 var PartTypes = map[string]Part{
-	"body":   BodyPart,
-	"header": HeaderPart,
-	"all":    AllPart,
+	"body":       BodyPart,
+	"header":     HeaderPart,
+	"all":        AllPart,
+	"answer":     AnswerPart,
+	"authority":  AuthorityPart,
+	"additional": AdditionalPart,
 }
 
 // GetPart returns the part of the matcher
@@ -119,6 +178,15 @@ func (m *Matcher) GetPart() Part {
 	return m.part
 }
 
+// GetMinMatches returns the minimum number of responses the matcher must match in race mode
+func (m *Matcher) GetMinMatches() int {
+	if m.MinMatches <= 0 {
+		return 1
+	}
+
+	return m.MinMatches
+}
+
 // isNegative reverts the results of the match if the matcher
 // is of type negative.
 func (m *Matcher) isNegative(data bool) bool {
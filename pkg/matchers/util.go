@@ -9,7 +9,8 @@ import (
 	"github.com/miekg/dns"
 )
 
-func httpToMap(resp *http.Response, body, headers string) (m map[string]interface{}) {
+// HTTPToMap returns the fields of a http response made available to dsl expressions
+func HTTPToMap(resp *http.Response, body, headers string) (m map[string]interface{}) {
 	m = make(map[string]interface{})
 
 	m["content_length"] = resp.ContentLength
@@ -30,7 +31,17 @@ func httpToMap(resp *http.Response, body, headers string) (m map[string]interfac
 	return m
 }
 
-func dnsToMap(msg *dns.Msg) (m map[string]interface{}) {
+// ContentType returns the content type of a http response, if any
+func ContentType(resp *http.Response) string {
+	if resp == nil {
+		return ""
+	}
+
+	return resp.Header.Get("Content-Type")
+}
+
+// DNSToMap returns the fields of a dns response made available to dsl expressions
+func DNSToMap(msg *dns.Msg) (m map[string]interface{}) {
 	m = make(map[string]interface{})
 
 	m["rcode"] = msg.Rcode
@@ -67,3 +78,24 @@ func dnsToMap(msg *dns.Msg) (m map[string]interface{}) {
 
 	return m
 }
+
+// NetworkToMap returns the data received from a network connection made available to dsl expressions
+func NetworkToMap(data string) (m map[string]interface{}) {
+	m = make(map[string]interface{})
+
+	m["data"] = data
+	m["raw"] = data
+
+	return m
+}
+
+// FileToMap returns the path and the content of a file made available to dsl expressions
+func FileToMap(path, data string) (m map[string]interface{}) {
+	m = make(map[string]interface{})
+
+	m["path"] = path
+	m["data"] = data
+	m["raw"] = data
+
+	return m
+}
@@ -0,0 +1,3 @@
+// Package query implements jq-style queries on json documents and xpath
+// queries on html and xml documents, used by the extractors and matchers.
+package query
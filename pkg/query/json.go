@@ -0,0 +1,319 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonStepType is the type of a step of a json query
+type jsonStepType int
+
+const (
+	// keyStep selects the value of a key of an object
+	keyStep jsonStepType = iota + 1
+	// indexStep selects an element of an array
+	indexStep
+	// iterateStep selects all the elements of an array or the values of an object
+	iterateStep
+)
+
+// jsonStep is a step of a json query
+type jsonStep struct {
+	stepType jsonStepType
+	key      string
+	index    int
+}
+
+// JSONQuery is a compiled query selecting values in a json document. It supports the
+// paths of the jq syntax, with the following grammar:
+//
+//	query = path { "|" path }
+//	path  = "." | step { step }
+//	step  = "." key | "." string | [ "." ] "[" [ string | index ] "]"
+//	key   = ( letter | "_" ) { letter | digit | "_" }
+//	index = [ "-" ] digit { digit }
+//
+// where string is a double quoted json string and the first step of a path starts with a
+// dot. Spaces are allowed around the pipes and inside the brackets. The leading dot of a
+// query starting with a key can be omitted, like data.items[0]. The rest of the jq syntax,
+// like slices, optional steps, recursion, operators and functions, is rejected.
+type JSONQuery struct {
+	steps []jsonStep
+}
+
+// ParseJSON compiles a query
+func ParseJSON(query string) (*JSONQuery, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("empty json query")
+	}
+
+	if isKeyStart(query[0]) {
+		query = "." + query
+	}
+
+	compiled := &JSONQuery{}
+
+	// A path is expected at the start of the query and after each pipe
+	expectPath := true
+
+	for i := 0; i < len(query); {
+		switch c := query[i]; {
+		case c == '|':
+			if expectPath {
+				return nil, fmt.Errorf("invalid json query %s: expected path before |", query)
+			}
+
+			expectPath = true
+			i = skipSpaces(query, i+1)
+		case c == ' ' || c == '\t':
+			i = skipSpaces(query, i)
+
+			if i < len(query) && query[i] != '|' {
+				return nil, fmt.Errorf("invalid json query %s: unexpected space", query)
+			}
+		case c == '.':
+			i++
+
+			switch {
+			case i < len(query) && query[i] == '"':
+				key, n, err := readQuoted(query[i:])
+				if err != nil {
+					return nil, fmt.Errorf("invalid json query %s: %s", query, err)
+				}
+
+				compiled.steps = append(compiled.steps, jsonStep{stepType: keyStep, key: key})
+				i += n
+			case i < len(query) && isKeyStart(query[i]):
+				start := i
+				for i < len(query) && isKeyChar(query[i]) {
+					i++
+				}
+
+				compiled.steps = append(compiled.steps, jsonStep{stepType: keyStep, key: query[start:i]})
+			case i < len(query) && query[i] == '[':
+				// The bracket is parsed as the next step
+			case expectPath && (i == len(query) || query[i] == ' ' || query[i] == '\t' || query[i] == '|'):
+				// A single dot selects the whole value
+			default:
+				return nil, fmt.Errorf("invalid json query %s: expected key after .", query)
+			}
+
+			expectPath = false
+		case c == '[' && !expectPath:
+			step, n, err := parseJSONBracket(query[i:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid json query %s: %s", query, err)
+			}
+
+			compiled.steps = append(compiled.steps, step)
+			i += n
+		default:
+			return nil, fmt.Errorf("invalid json query %s: unexpected character %q", query, c)
+		}
+	}
+
+	if expectPath {
+		return nil, fmt.Errorf("invalid json query %s: expected path after |", query)
+	}
+
+	return compiled, nil
+}
+
+// parseJSONBracket parses a [] step, returning it with its length
+func parseJSONBracket(data string) (jsonStep, int, error) {
+	i := skipSpaces(data, 1)
+
+	if i < len(data) && data[i] == '"' {
+		key, n, err := readQuoted(data[i:])
+		if err != nil {
+			return jsonStep{}, 0, err
+		}
+
+		i = skipSpaces(data, i+n)
+		if i == len(data) || data[i] != ']' {
+			return jsonStep{}, 0, fmt.Errorf("unclosed bracket")
+		}
+
+		return jsonStep{stepType: keyStep, key: key}, i + 1, nil
+	}
+
+	end := strings.IndexByte(data, ']')
+	if end < 0 {
+		return jsonStep{}, 0, fmt.Errorf("unclosed bracket")
+	}
+
+	inner := strings.TrimSpace(data[1:end])
+	if inner == "" {
+		return jsonStep{stepType: iterateStep}, end + 1, nil
+	}
+
+	if !isIndex(inner) {
+		return jsonStep{}, 0, fmt.Errorf("invalid index %s", inner)
+	}
+
+	index, err := strconv.Atoi(inner)
+	if err != nil {
+		return jsonStep{}, 0, fmt.Errorf("invalid index %s", inner)
+	}
+
+	return jsonStep{stepType: indexStep, index: index}, end + 1, nil
+}
+
+// skipSpaces returns the index of the first character from start which is not a space
+func skipSpaces(data string, start int) int {
+	for start < len(data) && (data[start] == ' ' || data[start] == '\t') {
+		start++
+	}
+
+	return start
+}
+
+// isIndex returns true if the data is an integer, optionally negative
+func isIndex(data string) bool {
+	data = strings.TrimPrefix(data, "-")
+	if data == "" {
+		return false
+	}
+
+	for i := 0; i < len(data); i++ {
+		if data[i] < '0' || data[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// readQuoted reads a double quoted string, returning it unquoted with its length
+func readQuoted(data string) (string, int, error) {
+	for i := 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			value, err := strconv.Unquote(data[:i+1])
+			if err != nil {
+				return "", 0, err
+			}
+
+			return value, i + 1, nil
+		}
+	}
+
+	return "", 0, fmt.Errorf("unclosed string")
+}
+
+// isKeyStart returns true if the character can start a key written without quotes
+func isKeyStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isKeyChar returns true if the character can be part of a key written without quotes
+func isKeyChar(c byte) bool {
+	return isKeyStart(c) || (c >= '0' && c <= '9')
+}
+
+// Run returns the values selected by the query in a decoded json document. As with jq, missing
+// keys and indexes select null values, while steps not applying to the type of a value select nothing.
+func (q *JSONQuery) Run(document interface{}) []interface{} {
+	values := []interface{}{document}
+
+	for _, step := range q.steps {
+		var selected []interface{}
+
+		for _, value := range values {
+			selected = append(selected, step.apply(value)...)
+		}
+
+		values = selected
+	}
+
+	return values
+}
+
+// apply returns the values selected by the step in a value
+func (s jsonStep) apply(value interface{}) []interface{} {
+	switch s.stepType {
+	case keyStep:
+		switch v := value.(type) {
+		case map[string]interface{}:
+			return []interface{}{v[s.key]}
+		case nil:
+			return []interface{}{nil}
+		}
+	case indexStep:
+		switch v := value.(type) {
+		case []interface{}:
+			index := s.index
+			if index < 0 {
+				index += len(v)
+			}
+
+			if index < 0 || index >= len(v) {
+				return []interface{}{nil}
+			}
+
+			return []interface{}{v[index]}
+		case nil:
+			return []interface{}{nil}
+		}
+	case iterateStep:
+		switch v := value.(type) {
+		case []interface{}:
+			return v
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+
+			sort.Strings(keys)
+
+			values := make([]interface{}, 0, len(v))
+			for _, key := range keys {
+				values = append(values, v[key])
+			}
+
+			return values
+		}
+	}
+
+	return nil
+}
+
+// ParseJSONDocument decodes a json document
+func ParseJSONDocument(data string) (interface{}, error) {
+	var document interface{}
+
+	if err := json.Unmarshal([]byte(data), &document); err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+// JSONValueToString formats a value selected in a json document. Strings and numbers
+// are returned as is, objects and arrays as json. It returns false for null values.
+func JSONValueToString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+
+	return string(data), true
+}
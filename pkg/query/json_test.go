@@ -0,0 +1,85 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func runJSON(t *testing.T, query, data string) []string {
+	compiled, err := ParseJSON(query)
+	require.Nil(t, err, "Could not parse json query %s", query)
+
+	document, err := ParseJSONDocument(data)
+	require.Nil(t, err, "Could not parse json document")
+
+	var results []string
+
+	for _, value := range compiled.Run(document) {
+		if result, ok := JSONValueToString(value); ok {
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+func TestJSONQuery(t *testing.T) {
+	data := `{"data": {"items": [{"id": 1, "name": "a"}, {"id": 2.5, "name": "b"}], "enabled": true},
+		"x-key": "dash", "a.b": "dot", "nested": {"k": [1, [2, 3]]}, "empty": null}`
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{".data.items[0].name", []string{"a"}},
+		{"data.items[1].id", []string{"2.5"}},
+		{".data.items[-1].name", []string{"b"}},
+		{".data.items[].id", []string{"1", "2.5"}},
+		{".data.items[] | .name", []string{"a", "b"}},
+		{".data.enabled", []string{"true"}},
+		{". | .data.enabled", []string{"true"}},
+		{`.["x-key"]`, []string{"dash"}},
+		{`.[ "x-key" ]`, []string{"dash"}},
+		{`."a.b"`, []string{"dot"}},
+		{".nested.k[1]", []string{"[2,3]"}},
+		{".nested", []string{`{"k":[1,[2,3]]}`}},
+		{".nested[]", []string{"[1,[2,3]]"}},
+		{".missing", nil},
+		{".missing.deeper[0]", nil},
+		{".empty", nil},
+		{".data.items[5]", nil},
+		{".data.enabled.key", nil},
+		{".data.enabled[]", nil},
+	}
+
+	for _, test := range tests {
+		results := runJSON(t, test.query, data)
+		require.Equal(t, test.expected, results, "Could not run json query %s", test.query)
+	}
+}
+
+func TestJSONQueryDocument(t *testing.T) {
+	results := runJSON(t, ".", `"value"`)
+	require.Equal(t, []string{"value"}, results, "Could not select whole document")
+
+	results = runJSON(t, ".[]", `{"b": 2, "a": 1, "c": "x"}`)
+	require.Equal(t, []string{"1", "2", "x"}, results, "Could not iterate object values by key")
+}
+
+func TestInvalidJSONQueries(t *testing.T) {
+	// Anything outside of the supported grammar is rejected, like keys which
+	// jq doesn't parse as keys without quotes, slices or optional steps.
+	queries := []string{"", "   ", ".a..b", ".a[", ".a[x]", `.["a"`, `."a`, ".a | b", ".a |", ".a b!",
+		".x-key", ".$a", ".1a", ".a .b", "[0]", ".a.", "| .a", ".a | | .b", "..", ".[1:2]", ".a?", ".[+1]", `.["a" x]`}
+
+	for _, query := range queries {
+		_, err := ParseJSON(query)
+		require.NotNil(t, err, "Could parse invalid json query %q", query)
+	}
+}
+
+func TestInvalidJSONDocument(t *testing.T) {
+	_, err := ParseJSONDocument(`{"a": `)
+	require.NotNil(t, err, "Could parse invalid json document")
+}
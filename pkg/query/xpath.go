@@ -0,0 +1,700 @@
+package query
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// XPath is a compiled xpath expression selecting nodes in a html or xml document. It
+// supports a subset of xpath 1.0, with the following grammar:
+//
+//	path      = [ "/" | "//" ] step { ( "/" | "//" ) step }
+//	step      = test { "[" predicate "]" } | "." | ".."
+//	test      = name | "*" | "text()" | "node()" | "@" name | "@*"
+//	predicate = position | "last()" | condition
+//	condition = term { ( " and " | " or " ) term }
+//	term      = operand | operand ( "=" | "!=" ) operand | function "(" operand "," operand ")"
+//	function  = "contains" | "starts-with"
+//	operand   = literal | number | "@" name | "text()" | "." | name
+//
+// where names are element or attribute names without namespace prefix, literals are quoted
+// with ' or ", positions are positive integers and and has precedence over or. Relative
+// paths are evaluated from the document. The rest of xpath, like the other axes, the other
+// functions, unions, arithmetic and parentheses, is rejected.
+type XPath struct {
+	steps []*xpathStep
+}
+
+// xpathStep is a step of an xpath expression
+type xpathStep struct {
+	descendant bool
+	test       string
+	predicates []xpathPredicate
+}
+
+// xpathPredicate filters the nodes selected by a step given their position
+type xpathPredicate func(node *html.Node, position, size int) bool
+
+// ParseXPath compiles an xpath expression
+func ParseXPath(expression string) (*XPath, error) {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return nil, fmt.Errorf("empty xpath")
+	}
+
+	compiled := &XPath{}
+
+	descendant := false
+	rest := expression
+
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "//"):
+			descendant = true
+			rest = rest[2:]
+
+			continue
+		case strings.HasPrefix(rest, "/"):
+			rest = rest[1:]
+
+			continue
+		}
+
+		end := splitStep(rest)
+		step, err := parseXPathStep(rest[:end], descendant)
+		if err != nil {
+			return nil, fmt.Errorf("invalid xpath %s: %s", expression, err)
+		}
+
+		compiled.steps = append(compiled.steps, step)
+		descendant = false
+		rest = rest[end:]
+	}
+
+	if len(compiled.steps) == 0 {
+		return nil, fmt.Errorf("invalid xpath %s: no step", expression)
+	}
+
+	return compiled, nil
+}
+
+// splitStep returns the end of the first step of a path, ignoring the slashes in predicates
+func splitStep(path string) int {
+	depth := 0
+	quote := byte(0)
+
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == '/' && depth == 0:
+			return i
+		}
+	}
+
+	return len(path)
+}
+
+// parseXPathStep parses a node test with its predicates
+func parseXPathStep(step string, descendant bool) (*xpathStep, error) {
+	compiled := &xpathStep{descendant: descendant}
+
+	end := strings.IndexByte(step, '[')
+	if end < 0 {
+		end = len(step)
+	}
+
+	compiled.test = strings.TrimSpace(step[:end])
+	if compiled.test == "" {
+		return nil, fmt.Errorf("empty step")
+	}
+
+	if !isNodeTest(compiled.test) {
+		return nil, fmt.Errorf("unsupported step %s", compiled.test)
+	}
+
+	rest := step[end:]
+
+	for rest != "" {
+		if rest[0] != '[' {
+			return nil, fmt.Errorf("unexpected %s", rest)
+		}
+
+		close := matchingBracket(rest)
+		if close < 0 {
+			return nil, fmt.Errorf("unclosed predicate")
+		}
+
+		predicate, err := parsePredicate(strings.TrimSpace(rest[1:close]))
+		if err != nil {
+			return nil, err
+		}
+
+		compiled.predicates = append(compiled.predicates, predicate)
+		rest = strings.TrimSpace(rest[close+1:])
+	}
+
+	if len(compiled.predicates) > 0 && (compiled.test == "." || compiled.test == "..") {
+		return nil, fmt.Errorf("predicates are not supported on %s", compiled.test)
+	}
+
+	return compiled, nil
+}
+
+// isNodeTest returns true if the data is a supported node test
+func isNodeTest(data string) bool {
+	switch data {
+	case ".", "..", "*", "text()", "node()", "@*":
+		return true
+	}
+
+	return isName(strings.TrimPrefix(data, "@"))
+}
+
+// matchingBracket returns the index of the bracket closing the one at the start of the data
+func matchingBracket(data string) int {
+	depth := 0
+	quote := byte(0)
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// parsePredicate parses the content of a predicate
+func parsePredicate(predicate string) (xpathPredicate, error) {
+	if isNumber(predicate) {
+		position, err := strconv.Atoi(predicate)
+		if err != nil || position <= 0 {
+			return nil, fmt.Errorf("invalid position %s", predicate)
+		}
+
+		return func(node *html.Node, p, size int) bool { return p == position }, nil
+	}
+
+	if predicate == "last()" {
+		return func(node *html.Node, p, size int) bool { return p == size }, nil
+	}
+
+	condition, err := parseCondition(predicate)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(node *html.Node, p, size int) bool { return condition(node) }, nil
+}
+
+// xpathCondition is a condition on a node
+type xpathCondition func(node *html.Node) bool
+
+// parseCondition parses a condition, with or having a lower precedence than and
+func parseCondition(condition string) (xpathCondition, error) {
+	if parts := splitOutsideQuotes(condition, " or "); len(parts) > 1 {
+		conditions, err := parseConditions(parts)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(node *html.Node) bool {
+			for _, c := range conditions {
+				if c(node) {
+					return true
+				}
+			}
+
+			return false
+		}, nil
+	}
+
+	if parts := splitOutsideQuotes(condition, " and "); len(parts) > 1 {
+		conditions, err := parseConditions(parts)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(node *html.Node) bool {
+			for _, c := range conditions {
+				if !c(node) {
+					return false
+				}
+			}
+
+			return true
+		}, nil
+	}
+
+	return parseTerm(strings.TrimSpace(condition))
+}
+
+// parseConditions parses several conditions
+func parseConditions(parts []string) ([]xpathCondition, error) {
+	conditions := make([]xpathCondition, 0, len(parts))
+
+	for _, part := range parts {
+		condition, err := parseCondition(part)
+		if err != nil {
+			return nil, err
+		}
+
+		conditions = append(conditions, condition)
+	}
+
+	return conditions, nil
+}
+
+// parseTerm parses a function call, a comparison or an existence test
+func parseTerm(term string) (xpathCondition, error) {
+	for _, function := range []string{"contains", "starts-with"} {
+		if !strings.HasPrefix(term, function+"(") || !strings.HasSuffix(term, ")") {
+			continue
+		}
+
+		args := splitOutsideQuotes(term[len(function)+1:len(term)-1], ",")
+		if len(args) != 2 {
+			return nil, fmt.Errorf("%s expects 2 arguments", function)
+		}
+
+		left, err := parseOperand(strings.TrimSpace(args[0]))
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := parseOperand(strings.TrimSpace(args[1]))
+		if err != nil {
+			return nil, err
+		}
+
+		test := strings.Contains
+		if function == "starts-with" {
+			test = strings.HasPrefix
+		}
+
+		return func(node *html.Node) bool {
+			return compareOperands(node, left, right, test)
+		}, nil
+	}
+
+	for _, operator := range []string{"!=", "="} {
+		parts := splitOutsideQuotes(term, operator)
+		if len(parts) != 2 {
+			continue
+		}
+
+		left, err := parseOperand(strings.TrimSpace(parts[0]))
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := parseOperand(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, err
+		}
+
+		equal := operator == "="
+
+		return func(node *html.Node) bool {
+			return compareOperands(node, left, right, func(l, r string) bool { return (l == r) == equal })
+		}, nil
+	}
+
+	operand, err := parseOperand(term)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(node *html.Node) bool {
+		return len(operand(node)) > 0
+	}, nil
+}
+
+// xpathOperand returns the values of an operand for a node, none if it doesn't exist
+type xpathOperand func(node *html.Node) []string
+
+// parseOperand parses a literal, @name, text(), . or a child element name
+func parseOperand(operand string) (xpathOperand, error) {
+	switch {
+	case operand == "":
+		return nil, fmt.Errorf("empty operand")
+	case len(operand) > 1 && (operand[0] == '\'' || operand[0] == '"') && operand[len(operand)-1] == operand[0]:
+		literal := []string{operand[1 : len(operand)-1]}
+
+		return func(node *html.Node) []string { return literal }, nil
+	case isNumber(operand):
+		return func(node *html.Node) []string { return []string{operand} }, nil
+	case strings.HasPrefix(operand, "@") && isName(operand[1:]):
+		name := operand[1:]
+
+		return func(node *html.Node) []string {
+			if value, ok := Attribute(node, name); ok {
+				return []string{value}
+			}
+
+			return nil
+		}, nil
+	case operand == "text()":
+		return func(node *html.Node) []string {
+			var values []string
+
+			for child := node.FirstChild; child != nil; child = child.NextSibling {
+				if child.Type == html.TextNode {
+					values = append(values, child.Data)
+				}
+			}
+
+			return values
+		}, nil
+	case operand == ".":
+		return func(node *html.Node) []string { return []string{Text(node)} }, nil
+	case isName(operand):
+		return func(node *html.Node) []string {
+			var values []string
+
+			for child := node.FirstChild; child != nil; child = child.NextSibling {
+				if child.Type == html.ElementNode && strings.EqualFold(child.Data, operand) {
+					values = append(values, Text(child))
+				}
+			}
+
+			return values
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported operand %s", operand)
+}
+
+// compareOperands returns true if the test is true for any pair of values of the operands
+func compareOperands(node *html.Node, left, right xpathOperand, test func(l, r string) bool) bool {
+	rightValues := right(node)
+
+	for _, l := range left(node) {
+		for _, r := range rightValues {
+			if test(l, r) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// splitOutsideQuotes splits the data around the separators which are not in quotes or brackets
+func splitOutsideQuotes(data, separator string) []string {
+	var parts []string
+
+	depth := 0
+	quote := byte(0)
+	start := 0
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case depth == 0 && strings.HasPrefix(data[i:], separator):
+			// != contains =, so = can't be preceded by !
+			if separator == "=" && i > 0 && data[i-1] == '!' {
+				continue
+			}
+
+			parts = append(parts, data[start:i])
+			start = i + len(separator)
+			i += len(separator) - 1
+		}
+	}
+
+	return append(parts, data[start:])
+}
+
+// isNumber returns true if the data is a number, optionally negative and with decimals
+func isNumber(data string) bool {
+	data = strings.TrimPrefix(data, "-")
+
+	if index := strings.IndexByte(data, '.'); index >= 0 {
+		return isDigits(data[:index]) && isDigits(data[index+1:])
+	}
+
+	return isDigits(data)
+}
+
+// isDigits returns true if the data is a non empty sequence of digits
+func isDigits(data string) bool {
+	for i := 0; i < len(data); i++ {
+		if data[i] < '0' || data[i] > '9' {
+			return false
+		}
+	}
+
+	return data != ""
+}
+
+// isName returns true if the data is a valid element or attribute name without namespace prefix
+func isName(data string) bool {
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && (c == '-' || c == '.' || (c >= '0' && c <= '9')))) {
+			return false
+		}
+	}
+
+	return data != ""
+}
+
+// Select returns the nodes selected by the expression in a document. Attributes
+// are returned as text nodes with the element they belong to as parent.
+func (x *XPath) Select(document *html.Node) []*html.Node {
+	nodes := []*html.Node{document}
+
+	for _, step := range x.steps {
+		var selected []*html.Node
+
+		seen := make(map[*html.Node]struct{})
+
+		for _, node := range nodes {
+			contexts := []*html.Node{node}
+			if step.descendant {
+				contexts = descendantsOrSelf(node)
+			}
+
+			for _, context := range contexts {
+				for _, candidate := range step.apply(context) {
+					if _, ok := seen[candidate]; ok {
+						continue
+					}
+
+					seen[candidate] = struct{}{}
+					selected = append(selected, candidate)
+				}
+			}
+		}
+
+		nodes = selected
+	}
+
+	return nodes
+}
+
+// apply returns the nodes selected by the step from a context node
+func (s *xpathStep) apply(context *html.Node) []*html.Node {
+	var candidates []*html.Node
+
+	switch {
+	case s.test == ".":
+		candidates = []*html.Node{context}
+	case s.test == "..":
+		if context.Parent != nil {
+			candidates = []*html.Node{context.Parent}
+		}
+	case strings.HasPrefix(s.test, "@"):
+		name := s.test[1:]
+
+		for _, attr := range context.Attr {
+			if name == "*" || strings.EqualFold(attr.Key, name) {
+				candidates = append(candidates, &html.Node{Type: html.TextNode, Data: attr.Val, Parent: context})
+			}
+		}
+	default:
+		for child := context.FirstChild; child != nil; child = child.NextSibling {
+			if s.matches(child) {
+				candidates = append(candidates, child)
+			}
+		}
+	}
+
+	for _, predicate := range s.predicates {
+		var filtered []*html.Node
+
+		for i, candidate := range candidates {
+			if predicate(candidate, i+1, len(candidates)) {
+				filtered = append(filtered, candidate)
+			}
+		}
+
+		candidates = filtered
+	}
+
+	return candidates
+}
+
+// matches returns true if a child node matches the node test of the step
+func (s *xpathStep) matches(node *html.Node) bool {
+	switch s.test {
+	case "*":
+		return node.Type == html.ElementNode
+	case "text()":
+		return node.Type == html.TextNode
+	case "node()":
+		return node.Type == html.ElementNode || node.Type == html.TextNode
+	}
+
+	return node.Type == html.ElementNode && strings.EqualFold(node.Data, s.test)
+}
+
+// descendantsOrSelf returns a node and all its descendants in document order
+func descendantsOrSelf(node *html.Node) []*html.Node {
+	nodes := []*html.Node{node}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		nodes = append(nodes, descendantsOrSelf(child)...)
+	}
+
+	return nodes
+}
+
+// Attribute returns the value of an attribute of a node, and false if the node doesn't have it
+func Attribute(node *html.Node, name string) (string, bool) {
+	for _, attr := range node.Attr {
+		if strings.EqualFold(attr.Key, name) {
+			return attr.Val, true
+		}
+	}
+
+	return "", false
+}
+
+// Text returns the text of a node and all its descendants
+func Text(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+
+	builder := &strings.Builder{}
+
+	for _, descendant := range descendantsOrSelf(node) {
+		if descendant.Type == html.TextNode {
+			builder.WriteString(descendant.Data)
+		}
+	}
+
+	return builder.String()
+}
+
+// ParseDocument parses a html or xml document. Documents served with a xml content
+// type are parsed as xml. Without a content type, documents starting with a xml
+// declaration or well formed documents whose root element isn't html are parsed as
+// xml, and the other documents as html.
+func ParseDocument(data, contentType string) (*html.Node, error) {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch {
+		case strings.Contains(mediaType, "html"):
+			return html.Parse(strings.NewReader(data))
+		case strings.HasSuffix(mediaType, "/xml") || strings.HasSuffix(mediaType, "+xml"):
+			return parseXML(data)
+		}
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(data), "<?xml") {
+		return parseXML(data)
+	}
+
+	if !isHTMLRoot(data) {
+		if document, err := parseXML(data); err == nil {
+			return document, nil
+		}
+	}
+
+	return html.Parse(strings.NewReader(data))
+}
+
+// isHTMLRoot returns true if the document has a html doctype or a html root element
+func isHTMLRoot(data string) bool {
+	decoder := xml.NewDecoder(strings.NewReader(data))
+	decoder.Strict = false
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+
+		switch t := token.(type) {
+		case xml.Directive:
+			if fields := strings.Fields(string(t)); len(fields) > 1 && strings.EqualFold(fields[0], "doctype") {
+				return strings.EqualFold(fields[1], "html")
+			}
+		case xml.StartElement:
+			return strings.EqualFold(t.Name.Local, "html")
+		}
+	}
+}
+
+// parseXML parses a well formed xml document into the same tree as a html
+// document, with the names of the elements and attributes kept without namespace.
+func parseXML(data string) (*html.Node, error) {
+	decoder := xml.NewDecoder(strings.NewReader(data))
+
+	document := &html.Node{Type: html.DocumentNode}
+	current := document
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return document, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			element := &html.Node{Type: html.ElementNode, Data: t.Name.Local}
+			for _, attr := range t.Attr {
+				element.Attr = append(element.Attr, html.Attribute{Key: attr.Name.Local, Val: attr.Value})
+			}
+
+			current.AppendChild(element)
+			current = element
+		case xml.EndElement:
+			if current.Parent != nil {
+				current = current.Parent
+			}
+		case xml.CharData:
+			current.AppendChild(&html.Node{Type: html.TextNode, Data: string(t)})
+		case xml.Comment:
+			current.AppendChild(&html.Node{Type: html.CommentNode, Data: string(t)})
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package query
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func selectXPath(t *testing.T, expression, data, contentType string) []string {
+	compiled, err := ParseXPath(expression)
+	require.Nil(t, err, "Could not parse xpath %s", expression)
+
+	document, err := ParseDocument(data, contentType)
+	require.Nil(t, err, "Could not parse document")
+
+	var results []string
+
+	for _, node := range compiled.Select(document) {
+		results = append(results, strings.TrimSpace(Text(node)))
+	}
+
+	return results
+}
+
+func TestXPathHTML(t *testing.T) {
+	data := `<!DOCTYPE html><html><head><title>Admin</title></head><body>
+		<ul id="menu"><li>one</li><li class="x">two</li><li class="x y">three</li></ul>
+		<form action="/login"><div><input type="password" name="pass"></div><input type="submit"></form>
+		<p>first<br>second</p></body></html>`
+
+	tests := []struct {
+		expression string
+		expected   []string
+	}{
+		{"/html/head/title", []string{"Admin"}},
+		{"//title/text()", []string{"Admin"}},
+		{"//li", []string{"one", "two", "three"}},
+		{"//ul/li[1]", []string{"one"}},
+		{"//ul/li[last()]", []string{"three"}},
+		{"//li[@class='x']", []string{"two"}},
+		{"//li[@class!='x']", []string{"three"}},
+		{"//li[@class]", []string{"two", "three"}},
+		{"//li[contains(@class, 'y')]", []string{"three"}},
+		{"//li[starts-with(., 't')]", []string{"two", "three"}},
+		{"//li[text()='one' or text()='three']", []string{"one", "three"}},
+		{"//li[@class and contains(., 'w')]", []string{"two"}},
+		{"//ul[li='two']/@id", []string{"menu"}},
+		{"//form[@action='/login']//input[@type='password']/@name", []string{"pass"}},
+		{"//input[@type='password']/../../@action", []string{"/login"}},
+		{"//form/*/input/@name", []string{"pass"}},
+		{"//p/text()", []string{"first", "second"}},
+		{"//ul/li[@class='x']/.", []string{"two"}},
+		{"//table", nil},
+	}
+
+	for _, test := range tests {
+		results := selectXPath(t, test.expression, data, "")
+		require.Equal(t, test.expected, results, "Could not select %s", test.expression)
+	}
+}
+
+func TestXPathXML(t *testing.T) {
+	rss := `<rss version="2.0"><channel><title>Feed</title>
+		<item><title>First</title><link>https://example.com/1</link></item>
+		<item><title>Second</title><link>https://example.com/2</link></item>
+		</channel></rss>`
+
+	// Without a content type, documents whose root element isn't html are parsed as xml
+	results := selectXPath(t, "//item/link", rss, "")
+	require.Equal(t, []string{"https://example.com/1", "https://example.com/2"}, results, "Could not select rss links")
+
+	results = selectXPath(t, "//item/link", rss, "application/rss+xml; charset=utf-8")
+	require.Equal(t, []string{"https://example.com/1", "https://example.com/2"}, results, "Could not select rss links with content type")
+
+	results = selectXPath(t, "/rss/@version", `<?xml version="1.0"?>`+rss, "")
+	require.Equal(t, []string{"2.0"}, results, "Could not select attribute of xml document")
+
+	// Namespaces are dropped from the names of the elements and attributes
+	soap := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+		<m:User xmlns:m="urn:users" m:id="7"><m:Name>admin</m:Name></m:User></soap:Body></soap:Envelope>`
+
+	results = selectXPath(t, "//User[@id='7']/Name", soap, "text/xml")
+	require.Equal(t, []string{"admin"}, results, "Could not select namespaced elements")
+
+	// CDATA sections are text
+	results = selectXPath(t, "//script", `<config><script><![CDATA[a < b]]></script></config>`, "")
+	require.Equal(t, []string{"a < b"}, results, "Could not select cdata text")
+}
+
+func TestXPathDocumentType(t *testing.T) {
+	// Elements of xml documents are not closed like html elements
+	data := `<root><p><div>inside</div></p><link>https://example.com</link></root>`
+
+	results := selectXPath(t, "//p/div", data, "application/xml")
+	require.Equal(t, []string{"inside"}, results, "Could not keep xml structure")
+
+	results = selectXPath(t, "//link", data, "")
+	require.Equal(t, []string{"https://example.com"}, results, "Could not sniff xml document")
+
+	// Documents served as html are parsed as html, where link is a void element
+	results = selectXPath(t, "//link", data, "text/html; charset=utf-8")
+	require.Equal(t, []string{""}, results, "Could not parse html document")
+
+	// Malformed documents without a content type are parsed as html
+	results = selectXPath(t, "//li", `<ul><li>one<li>two</ul>`, "")
+	require.Equal(t, []string{"one", "two"}, results, "Could not parse malformed html")
+
+	_, err := ParseDocument(`<root><a></root>`, "text/xml")
+	require.NotNil(t, err, "Could parse malformed xml document")
+}
+
+func TestInvalidXPaths(t *testing.T) {
+	// Anything outside of the supported subset is rejected instead of selecting nothing
+	expressions := []string{"", "//", "//form[", "//li[]", "//li[contains(@a)]", "//li[@a=]", "//li[a+b]",
+		"//soap:Body", "//li/following-sibling::li", "//a | //b", "count(//li)", "//li[0]", "//li[-1]",
+		"//li[not(@a)]", "//li[(@a)]", "//li[@a b='x']", "//li[@1a]", "//li/..[1]", "//1li"}
+
+	for _, expression := range expressions {
+		_, err := ParseXPath(expression)
+		require.NotNil(t, err, "Could parse invalid xpath %q", expression)
+	}
+}
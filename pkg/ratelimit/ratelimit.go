@@ -0,0 +1,138 @@
+// Package ratelimit limits the number of requests sent per second,
+// globally and for each host, with token buckets.
+package ratelimit
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Limiter limits the requests sent globally and for each host. A nil
+// limiter doesn't limit anything. It is safe for concurrent use.
+type Limiter struct {
+	// requests is first to be aligned for atomic operations on 32-bit platforms
+	requests uint64
+	started  time.Time
+
+	global      *bucket
+	perHostRate int
+	hosts       map[string]*bucket
+	mutex       sync.Mutex
+}
+
+// bucket is a token bucket allowing rate tokens per second, with bursts of up to rate tokens
+type bucket struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+	mutex  sync.Mutex
+}
+
+// New creates a limiter allowing rate requests per second globally and perHostRate requests
+// per second for each host. A rate of 0 disables the corresponding limit. It returns nil if
+// both limits are disabled.
+func New(rate, perHostRate int) *Limiter {
+	if rate <= 0 && perHostRate <= 0 {
+		return nil
+	}
+
+	limiter := &Limiter{perHostRate: perHostRate, hosts: make(map[string]*bucket), started: time.Now()}
+	if rate > 0 {
+		limiter.global = newBucket(rate)
+	}
+
+	return limiter
+}
+
+// newBucket creates a full token bucket
+func newBucket(rate int) *bucket {
+	return &bucket{rate: float64(rate), tokens: float64(rate), last: time.Now()}
+}
+
+// Take blocks until a request can be sent to the host. The host is the key of the per-host
+// limit, callers must use the same key for all the requests to a host, like hosterrors.Key.
+func (l *Limiter) Take(host string) {
+	if l == nil {
+		return
+	}
+
+	var wait time.Duration
+
+	if l.global != nil {
+		wait = l.global.reserve()
+	}
+
+	if l.perHostRate > 0 {
+		if hostWait := l.hostBucket(host).reserve(); hostWait > wait {
+			wait = hostWait
+		}
+	}
+
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+
+	atomic.AddUint64(&l.requests, 1)
+}
+
+// Requests returns the number of requests allowed since the limiter was created
+func (l *Limiter) Requests() uint64 {
+	if l == nil {
+		return 0
+	}
+
+	return atomic.LoadUint64(&l.requests)
+}
+
+// AverageRate returns the average number of requests per second since the limiter was created
+func (l *Limiter) AverageRate() float64 {
+	if l == nil {
+		return 0
+	}
+
+	elapsed := time.Since(l.started).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return float64(l.Requests()) / elapsed
+}
+
+// hostBucket returns the bucket of a host, creating it if needed
+func (l *Limiter) hostBucket(host string) *bucket {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	hostBucket, ok := l.hosts[host]
+	if !ok {
+		hostBucket = newBucket(l.perHostRate)
+		l.hosts[host] = hostBucket
+	}
+
+	return hostBucket
+}
+
+// reserve takes a token from the bucket and returns how long to wait
+// before using it. The bucket goes in debt if no token is available,
+// so concurrent callers are queued one after the other.
+func (b *bucket) reserve() time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := time.Now()
+
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+
+	b.last = now
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// takeDuration returns how long taking a request for the host blocked
+func takeDuration(limiter *Limiter, host string) time.Duration {
+	start := time.Now()
+	limiter.Take(host)
+
+	return time.Since(start)
+}
+
+// requireWait checks that a wait of the limiter is close to the expected one
+func requireWait(t *testing.T, expected, actual time.Duration, msgAndArgs ...interface{}) {
+	require.True(t, actual >= expected-20*time.Millisecond && actual < expected+150*time.Millisecond, msgAndArgs...)
+}
+
+func TestDisabledLimiter(t *testing.T) {
+	limiter := New(0, 0)
+	require.Nil(t, limiter, "Could create limiter without limits")
+
+	require.True(t, takeDuration(limiter, "a") < 10*time.Millisecond, "Could block without limiter")
+	require.Equal(t, uint64(0), limiter.Requests(), "Could count requests without limiter")
+	require.Equal(t, float64(0), limiter.AverageRate(), "Could get rate without limiter")
+}
+
+func TestBucketBurst(t *testing.T) {
+	b := newBucket(10)
+
+	// The bucket starts full with a second of tokens
+	for i := 0; i < 10; i++ {
+		require.Equal(t, time.Duration(0), b.reserve(), "Could not take token %d of burst", i)
+	}
+
+	// Then the callers are queued one token interval after the other
+	require.InDelta(t, 100*time.Millisecond, b.reserve(), float64(10*time.Millisecond), "Could not queue first caller after burst")
+	require.InDelta(t, 200*time.Millisecond, b.reserve(), float64(10*time.Millisecond), "Could not queue second caller after burst")
+
+	// The tokens are refilled over time, up to the burst
+	b.last = b.last.Add(-10 * time.Second)
+	require.Equal(t, time.Duration(0), b.reserve(), "Could not refill bucket")
+	require.InDelta(t, 9, b.tokens, 0.1, "Could refill bucket over its burst")
+}
+
+func TestPerHostLimit(t *testing.T) {
+	limiter := New(1000, 10)
+
+	for i := 0; i < 10; i++ {
+		require.True(t, takeDuration(limiter, "a") < 50*time.Millisecond, "Could not send burst to host")
+		require.True(t, takeDuration(limiter, "b") < 50*time.Millisecond, "Could not send burst to other host")
+	}
+
+	// The per-host limit is stricter than the global one
+	requireWait(t, 100*time.Millisecond, takeDuration(limiter, "a"), "Could not wait for per-host limit")
+	require.True(t, takeDuration(limiter, "c") < 50*time.Millisecond, "Could wait for per-host limit of other host")
+
+	require.Equal(t, uint64(22), limiter.Requests(), "Could not count requests")
+}
+
+func TestGlobalLimit(t *testing.T) {
+	limiter := New(10, 1000)
+
+	for i := 0; i < 10; i++ {
+		require.True(t, takeDuration(limiter, fmt.Sprintf("host-%d", i)) < 50*time.Millisecond, "Could not send global burst")
+	}
+
+	// The global limit is stricter than the per-host one, even for a new host
+	requireWait(t, 100*time.Millisecond, takeDuration(limiter, "new"), "Could not wait for global limit")
+	requireWait(t, 100*time.Millisecond, takeDuration(limiter, "new"), "Could not wait for global limit again")
+
+	require.Equal(t, uint64(12), limiter.Requests(), "Could not count requests")
+	require.True(t, limiter.AverageRate() > 0 && limiter.AverageRate() <= 12/0.2, "Could not compute average rate: %f", limiter.AverageRate())
+}
+
+func TestPerHostOnlyLimit(t *testing.T) {
+	limiter := New(0, 5)
+
+	for i := 0; i < 5; i++ {
+		limiter.Take("a")
+	}
+
+	requireWait(t, 200*time.Millisecond, takeDuration(limiter, "a"), "Could not wait for per-host limit without global limit")
+	require.Equal(t, uint64(6), limiter.Requests(), "Could not count requests")
+}
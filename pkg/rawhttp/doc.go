@@ -0,0 +1,3 @@
+// Package rawhttp sends HTTP requests written as raw bytes over plain
+// connections, for the cases the standard HTTP client doesn't support.
+package rawhttp
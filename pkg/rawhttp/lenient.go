@@ -0,0 +1,126 @@
+package rawhttp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Do sends a request to the host of a URL over a new connection and reads its response
+func Do(ctx context.Context, options *Options, rawURL string, request *Request) (*Response, error) {
+	conn, err := Dial(ctx, options, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if options.Timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(options.Timeout)); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := conn.Write(request.Data); err != nil {
+		return nil, err
+	}
+
+	return request.read(bufio.NewReader(conn))
+}
+
+// ReadResponseLenient reads a response without enforcing the HTTP syntax, as servers often
+// answer malformed requests with malformed responses. Lines may end with a bare line feed,
+// invalid status lines and headers are kept as far as possible, and the body is read until
+// the connection is closed when its length is unknown. Errors reading the body are ignored
+// and the part of the body received is returned.
+func ReadResponseLenient(reader *bufio.Reader, method string) (*Response, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &http.Response{
+		Status:  line,
+		Proto:   line,
+		Header:  make(http.Header),
+		Close:   true,
+		Request: &http.Request{Method: method},
+	}
+
+	// The status line is expected as "HTTP/1.1 200 OK" but may be anything
+	parts := strings.SplitN(line, " ", 3)
+	if len(parts) > 1 {
+		resp.Proto = parts[0]
+		resp.ProtoMajor, resp.ProtoMinor, _ = http.ParseHTTPVersion(parts[0])
+		resp.StatusCode, _ = strconv.Atoi(parts[1])
+
+		reason := http.StatusText(resp.StatusCode)
+		if len(parts) > 2 {
+			reason = parts[2]
+		}
+
+		resp.Status = parts[1] + " " + reason
+	}
+
+	for {
+		line, err := readLine(reader)
+		if err != nil || line == "" {
+			break
+		}
+
+		header := strings.SplitN(line, ":", 2)
+		if len(header) != 2 {
+			continue
+		}
+
+		resp.Header.Add(strings.TrimSpace(header[0]), strings.TrimSpace(header[1]))
+	}
+
+	data := readLenientBody(reader, resp)
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+	resp.ContentLength = int64(len(data))
+
+	return &Response{Response: resp, Data: data}, nil
+}
+
+// readLine reads a line ending with a line feed, with an optional carriage return
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readLenientBody reads as much of the body of a response as possible
+func readLenientBody(reader *bufio.Reader, resp *http.Response) []byte {
+	if resp.Request.Method == http.MethodHead || resp.StatusCode == http.StatusNoContent ||
+		resp.StatusCode == http.StatusNotModified || (resp.StatusCode >= 100 && resp.StatusCode < 200) {
+		return nil
+	}
+
+	if strings.EqualFold(resp.Header.Get("Transfer-Encoding"), "chunked") {
+		data, _ := ioutil.ReadAll(httputil.NewChunkedReader(reader))
+
+		return data
+	}
+
+	if length, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil && length >= 0 {
+		data := &bytes.Buffer{}
+		_, _ = io.CopyN(data, reader, length)
+
+		return data.Bytes()
+	}
+
+	data, _ := ioutil.ReadAll(reader)
+
+	return data
+}
@@ -0,0 +1,94 @@
+package rawhttp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// Pipeline sends the requests to the host of a URL over as few connections as possible.
+// Up to depth requests are written at once on a connection before their responses are
+// read in order. If the server closes a connection, the requests left without a response
+// are sent again over a new one. The responses received are returned in the order of the
+// requests, along with the error which stopped the pipeline, if any.
+func Pipeline(ctx context.Context, options *Options, rawURL string, requests []*Request, depth int) ([]*Response, error) {
+	if depth <= 0 {
+		depth = 1
+	}
+
+	responses := make([]*Response, 0, len(requests))
+
+	for len(responses) < len(requests) {
+		conn, err := Dial(ctx, options, rawURL)
+		if err != nil {
+			return responses, err
+		}
+
+		received, err := pipelineConn(conn, options, requests[len(responses):], depth)
+		conn.Close()
+
+		responses = append(responses, received...)
+
+		// Stop if the connection failed before any response, as a new
+		// connection is then likely to fail the same way.
+		if err != nil && len(received) == 0 {
+			return responses, err
+		}
+	}
+
+	return responses, nil
+}
+
+// errConnectionClosed is returned when the server closes the connection
+var errConnectionClosed = errors.New("connection closed by server")
+
+// pipelineConn sends the requests over a connection and returns the responses
+// received until all the requests are done or the connection is closed.
+func pipelineConn(conn net.Conn, options *Options, requests []*Request, depth int) ([]*Response, error) {
+	reader := bufio.NewReader(conn)
+	responses := make([]*Response, 0, len(requests))
+
+	for start := 0; start < len(requests); start += depth {
+		end := start + depth
+		if end > len(requests) {
+			end = len(requests)
+		}
+
+		batch := requests[start:end]
+
+		if options.Timeout > 0 {
+			if err := conn.SetDeadline(time.Now().Add(options.Timeout)); err != nil {
+				return responses, err
+			}
+		}
+
+		// Write the whole batch at once so the requests share packets when possible
+		buffer := &bytes.Buffer{}
+		for _, request := range batch {
+			buffer.Write(request.Data)
+		}
+
+		if _, err := conn.Write(buffer.Bytes()); err != nil {
+			return responses, err
+		}
+
+		for _, request := range batch {
+			resp, err := request.read(reader)
+			if err != nil {
+				return responses, err
+			}
+
+			responses = append(responses, resp)
+
+			// The server won't answer the other requests sent on this connection
+			if resp.Close {
+				return responses, errConnectionClosed
+			}
+		}
+	}
+
+	return responses, nil
+}
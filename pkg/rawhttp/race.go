@@ -0,0 +1,112 @@
+package rawhttp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// Race sends the same request over count connections at the same time, to test for race
+// conditions. The request is first written without its last byte on all the connections,
+// then the last bytes are written together so that the server gets the complete requests
+// at once. The responses received are returned, or an error if none was received.
+func Race(ctx context.Context, options *Options, rawURL string, request *Request, count int) ([]*Response, error) {
+	if len(request.Data) == 0 {
+		return nil, errors.New("empty request")
+	}
+
+	conns := make([]net.Conn, count)
+	errs := make([]error, count)
+
+	// Connect and write all but the last byte of the request on all the connections
+	var wg sync.WaitGroup
+
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			conn, err := Dial(ctx, options, rawURL)
+			if err != nil {
+				errs[i] = err
+
+				return
+			}
+
+			if options.Timeout > 0 {
+				if err := conn.SetDeadline(time.Now().Add(options.Timeout)); err != nil {
+					conn.Close()
+
+					errs[i] = err
+
+					return
+				}
+			}
+
+			if _, err := conn.Write(request.Data[:len(request.Data)-1]); err != nil {
+				conn.Close()
+
+				errs[i] = err
+
+				return
+			}
+
+			conns[i] = conn
+		}(i)
+	}
+
+	wg.Wait()
+
+	// Release the last bytes on all the connections at once
+	responses := make([]*Response, count)
+	start := make(chan struct{})
+	last := request.Data[len(request.Data)-1:]
+
+	for i, conn := range conns {
+		if conn == nil {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(i int, conn net.Conn) {
+			defer wg.Done()
+			defer conn.Close()
+
+			<-start
+
+			if _, err := conn.Write(last); err != nil {
+				errs[i] = err
+
+				return
+			}
+
+			responses[i], errs[i] = request.read(bufio.NewReader(conn))
+		}(i, conn)
+	}
+
+	close(start)
+	wg.Wait()
+
+	received := make([]*Response, 0, count)
+
+	var firstErr error
+
+	for i, resp := range responses {
+		if resp != nil {
+			received = append(received, resp)
+		} else if firstErr == nil {
+			firstErr = errs[i]
+		}
+	}
+
+	if len(received) == 0 {
+		return nil, firstErr
+	}
+
+	return received, nil
+}
@@ -0,0 +1,128 @@
+package rawhttp
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Options contains the configuration options for the raw connections
+type Options struct {
+	// Timeout is the time to wait for a connection or a response
+	Timeout time.Duration
+	// DialContext dials the connections. By default, a net.Dialer is used.
+	DialContext func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// Request is a raw request to send to a server
+type Request struct {
+	// Method is the method of the request, needed to read the response
+	Method string
+	// Data contains the bytes of the request
+	Data []byte
+	// Lenient reads the response to the request with ReadResponseLenient
+	Lenient bool
+}
+
+// Response is a response read from a server with its whole body
+type Response struct {
+	*http.Response
+	// Data contains the body of the response
+	Data []byte
+}
+
+// Dial connects to the host of a URL, using TLS for https URLs. The port
+// defaults to 80, or 443 with TLS, if the URL doesn't contain one.
+func Dial(ctx context.Context, options *Options, rawURL string) (net.Conn, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	useTLS := strings.EqualFold(parsed.Scheme, "https")
+
+	address := parsed.Host
+	if parsed.Port() == "" {
+		port := "80"
+		if useTLS {
+			port = "443"
+		}
+
+		address = net.JoinHostPort(parsed.Hostname(), port)
+	}
+
+	dialContext := options.DialContext
+	if dialContext == nil {
+		dialContext = (&net.Dialer{Timeout: options.Timeout}).DialContext
+	}
+
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	conn, err := dialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	if !useTLS {
+		return conn, nil
+	}
+
+	tlsConn := tls.Client(conn, &tls.Config{
+		ServerName:         parsed.Hostname(),
+		InsecureSkipVerify: true,
+	})
+
+	if options.Timeout > 0 {
+		if err := tlsConn.SetDeadline(time.Now().Add(options.Timeout)); err != nil {
+			conn.Close()
+
+			return nil, err
+		}
+	}
+
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+
+		return nil, fmt.Errorf("could not do tls handshake: %s", err)
+	}
+
+	return tlsConn, nil
+}
+
+// ReadResponse reads a response with its whole body from a connection
+func ReadResponse(reader *bufio.Reader, method string) (*Response, error) {
+	resp, err := http.ReadResponse(reader, &http.Request{Method: method})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{Response: resp, Data: data}, nil
+}
+
+// read reads the response to the request from a connection
+func (r *Request) read(reader *bufio.Reader) (*Response, error) {
+	if r.Lenient {
+		return ReadResponseLenient(reader, r.Method)
+	}
+
+	return ReadResponse(reader, r.Method)
+}
@@ -0,0 +1,232 @@
+package rawhttp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testOptions = &Options{Timeout: 5 * time.Second}
+
+// serveRaw starts a server writing a raw response to each connection once the
+// request headers are received, then closing the connection.
+func serveRaw(t *testing.T, response string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err, "Could not listen")
+
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+
+				reader := bufio.NewReader(conn)
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil || line == "\r\n" {
+						break
+					}
+				}
+
+				_, _ = conn.Write([]byte(response))
+			}(conn)
+		}
+	}()
+
+	return "http://" + listener.Addr().String()
+}
+
+func TestReadResponseLenient(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		status   int
+		header   string
+		body     string
+	}{
+		{"bare line feeds", "HTTP/1.1 200 OK\nX-Test: a\nContent-Length: 5\n\nhello trailing", 200, "a", "hello"},
+		{"malformed status line", "HTTP/1.1 abc\r\nX-Test: b\r\nContent-Length: 2\r\n\r\nok", 0, "b", "ok"},
+		{"status line without reason", "HTTP/1.0 404\r\nX-Test: c\r\n\r\nnot found", 404, "c", "not found"},
+		{"missing content length", "HTTP/1.1 200 OK\r\nX-Test: d\r\n\r\nread until\nthe connection is closed", 200, "d", "read until\nthe connection is closed"},
+		{"chunked body", "HTTP/1.1 200 OK\r\nX-Test: e\r\nTransfer-Encoding: chunked\r\n\r\n5\r\nhello\r\n6\r\n world\r\n0\r\n\r\n", 200, "e", "hello world"},
+		{"invalid header line", "HTTP/1.1 500 Internal Server Error\r\nnot a header\r\nX-Test: f\r\nContent-Length: 3\r\n\r\nerr", 500, "f", "err"},
+	}
+
+	for _, test := range tests {
+		address := serveRaw(t, test.response)
+
+		request := &Request{Method: http.MethodGet, Data: []byte("GET / HTTP/1.1\r\nHost: test\r\n\r\n"), Lenient: true}
+
+		resp, err := Do(context.Background(), testOptions, address, request)
+		require.Nil(t, err, "Could not read response with %s", test.name)
+		require.Equal(t, test.status, resp.StatusCode, "Could not read status with %s", test.name)
+		require.Equal(t, test.header, resp.Header.Get("X-Test"), "Could not read header with %s", test.name)
+		require.Equal(t, test.body, string(resp.Data), "Could not read body with %s", test.name)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		require.Nil(t, err, "Could not read body reader with %s", test.name)
+		require.Equal(t, test.body, string(body), "Could not read body reader with %s", test.name)
+	}
+}
+
+func TestReadResponseLenientHead(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("HTTP/1.1 200 OK\r\nContent-Length: 10\r\n\r\n"))
+
+	resp, err := ReadResponseLenient(reader, http.MethodHead)
+	require.Nil(t, err, "Could not read response to head request")
+	require.Empty(t, resp.Data, "Could read body of response to head request")
+
+	_, err = ReadResponseLenient(bufio.NewReader(strings.NewReader("")), http.MethodGet)
+	require.NotNil(t, err, "Could read empty response")
+}
+
+// servePipeline starts a server answering the requests of a connection in order with their
+// path as body. It closes the connections after closeAfter responses and counts them.
+func servePipeline(t *testing.T, closeAfter int, connections *int32) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err, "Could not listen")
+
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			atomic.AddInt32(connections, 1)
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+
+				reader := bufio.NewReader(conn)
+
+				for count := 1; ; count++ {
+					req, err := http.ReadRequest(reader)
+					if err != nil {
+						return
+					}
+
+					closing := count == closeAfter
+					connection := "keep-alive"
+
+					if closing {
+						connection = "close"
+					}
+
+					fmt.Fprintf(conn, "HTTP/1.1 200 OK\r\nConnection: %s\r\nContent-Length: %d\r\n\r\n%s", connection, len(req.URL.Path), req.URL.Path)
+
+					if closing {
+						// Drain the requests left so that closing doesn't reset the connection
+						// before the client reads the responses.
+						_ = conn.(*net.TCPConn).CloseWrite()
+						_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+						_, _ = io.Copy(ioutil.Discard, reader)
+
+						return
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	return "http://" + listener.Addr().String()
+}
+
+func TestPipelineReconnects(t *testing.T) {
+	var connections int32
+
+	address := servePipeline(t, 2, &connections)
+
+	var requests []*Request
+
+	for i := 0; i < 5; i++ {
+		requests = append(requests, &Request{Method: http.MethodGet, Data: []byte(fmt.Sprintf("GET /%d HTTP/1.1\r\nHost: test\r\n\r\n", i))})
+	}
+
+	responses, err := Pipeline(context.Background(), testOptions, address, requests, 5)
+	require.Nil(t, err, "Could not pipeline requests")
+	require.Len(t, responses, 5, "Could not get a response for each request")
+
+	for i, resp := range responses {
+		require.Equal(t, fmt.Sprintf("/%d", i), string(resp.Data), "Could not get responses in order")
+	}
+
+	require.Equal(t, int32(3), atomic.LoadInt32(&connections), "Could not reconnect after the connection was closed")
+}
+
+func TestPipelineDepth(t *testing.T) {
+	var connections int32
+
+	address := servePipeline(t, 0, &connections)
+
+	var requests []*Request
+
+	for i := 0; i < 7; i++ {
+		requests = append(requests, &Request{Method: http.MethodGet, Data: []byte(fmt.Sprintf("GET /%d HTTP/1.1\r\nHost: test\r\n\r\n", i))})
+	}
+
+	responses, err := Pipeline(context.Background(), testOptions, address, requests, 3)
+	require.Nil(t, err, "Could not pipeline requests")
+	require.Len(t, responses, 7, "Could not get a response for each request")
+	require.Equal(t, "/6", string(responses[6].Data), "Could not get last response")
+	require.Equal(t, int32(1), atomic.LoadInt32(&connections), "Could not send all the batches over one connection")
+}
+
+func TestRace(t *testing.T) {
+	var (
+		mutex    sync.Mutex
+		requests int
+	)
+
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests++
+		mutex.Unlock()
+
+		_, _ = w.Write([]byte("raced"))
+	})}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err, "Could not listen")
+
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	request := &Request{Method: http.MethodGet, Data: []byte("GET / HTTP/1.1\r\nHost: test\r\nConnection: close\r\n\r\n")}
+
+	responses, err := Race(context.Background(), testOptions, "http://"+listener.Addr().String(), request, 5)
+	require.Nil(t, err, "Could not race requests")
+	require.Len(t, responses, 5, "Could not get a response for each connection")
+
+	for _, resp := range responses {
+		require.Equal(t, http.StatusOK, resp.StatusCode, "Could not get raced response status")
+		require.Equal(t, "raced", string(resp.Data), "Could not get raced response body")
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	require.Equal(t, 5, requests, "Could not send the request over each connection")
+
+	_, err = Race(context.Background(), testOptions, "http://"+listener.Addr().String(), &Request{}, 2)
+	require.NotNil(t, err, "Could race empty request")
+}
@@ -0,0 +1,3 @@
+// Package reporting contains the report formats the results
+// of a scan can be exported to.
+package reporting
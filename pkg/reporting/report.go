@@ -0,0 +1,344 @@
+package reporting
+
+import (
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/executer"
+)
+
+// severities contains the known severities from the most to the least severe
+var severities = []string{"critical", "high", "medium", "low", "info"}
+
+// unknownSeverity is the group of the results with a missing or unknown severity
+const unknownSeverity = "unknown"
+
+// ReportWriter collects the results of a scan and writes them as a human readable report.
+// The report is a directory with a Markdown and a self-contained HTML index summarizing
+// the results per severity, and a Markdown page for each result.
+type ReportWriter struct {
+	events []*executer.ResultEvent
+	mutex  sync.Mutex
+}
+
+// reportFinding is a result of the report with its page
+type reportFinding struct {
+	*executer.ResultEvent
+	ID       string
+	Severity string
+	Page     string
+}
+
+// reportSummary is the number of results for a severity
+type reportSummary struct {
+	Severity string
+	Count    int
+}
+
+// NewReportWriter creates a new report writer
+func NewReportWriter() *ReportWriter {
+	return &ReportWriter{}
+}
+
+// Write adds a result event to the report
+func (w *ReportWriter) Write(event *executer.ResultEvent) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.events = append(w.events, event)
+
+	return nil
+}
+
+// Close does nothing as the report is written with WriteDirectory
+func (w *ReportWriter) Close() error {
+	return nil
+}
+
+// WriteDirectory writes the report with the results collected so far to a directory
+func (w *ReportWriter) WriteDirectory(directory string) error {
+	w.mutex.Lock()
+	findings := newReportFindings(w.events)
+	w.mutex.Unlock()
+
+	if err := os.MkdirAll(filepath.Join(directory, "findings"), 0755); err != nil {
+		return err
+	}
+
+	summary := newReportSummary(findings)
+	generated := time.Now()
+
+	for _, finding := range findings {
+		err := ioutil.WriteFile(filepath.Join(directory, finding.Page), []byte(markdownFinding(finding)), 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := ioutil.WriteFile(filepath.Join(directory, "index.md"), []byte(markdownIndex(generated, summary, findings)), 0644)
+	if err != nil {
+		return err
+	}
+
+	output, err := os.Create(filepath.Join(directory, "index.html"))
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+
+	return htmlIndex.Execute(output, map[string]interface{}{
+		"Generated": generated.Format(time.RFC1123),
+		"Summary":   summary,
+		"Findings":  findings,
+	})
+}
+
+// unsafePageChars matches the characters not allowed in the name of a finding page
+var unsafePageChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// newReportFindings sorts the result events by severity and gives each of them a page
+func newReportFindings(events []*executer.ResultEvent) []*reportFinding {
+	findings := make([]*reportFinding, 0, len(events))
+
+	for _, event := range events {
+		findings = append(findings, &reportFinding{ResultEvent: event, Severity: severityOf(event)})
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if a, b := severityRank(findings[i].Severity), severityRank(findings[j].Severity); a != b {
+			return a < b
+		}
+
+		if findings[i].Template != findings[j].Template {
+			return findings[i].Template < findings[j].Template
+		}
+
+		return findings[i].Matched < findings[j].Matched
+	})
+
+	for i, finding := range findings {
+		finding.ID = fmt.Sprintf("%04d", i+1)
+		finding.Page = "findings/" + finding.ID + "-" + unsafePageChars.ReplaceAllString(finding.Template, "_") + ".md"
+	}
+
+	return findings
+}
+
+// newReportSummary counts the findings for each severity
+func newReportSummary(findings []*reportFinding) []*reportSummary {
+	counts := make(map[string]int)
+	for _, finding := range findings {
+		counts[finding.Severity]++
+	}
+
+	summary := make([]*reportSummary, 0, len(severities)+1)
+	for _, severity := range severities {
+		summary = append(summary, &reportSummary{Severity: severity, Count: counts[severity]})
+	}
+
+	if count := counts[unknownSeverity]; count > 0 {
+		summary = append(summary, &reportSummary{Severity: unknownSeverity, Count: count})
+	}
+
+	return summary
+}
+
+// severityOf returns the known severity of a result event
+func severityOf(event *executer.ResultEvent) string {
+	severity := strings.ToLower(strings.TrimSpace(event.Severity))
+	if severityRank(severity) == len(severities) {
+		return unknownSeverity
+	}
+
+	return severity
+}
+
+// severityRank returns the position of a severity from the most severe one
+func severityRank(severity string) int {
+	for i, value := range severities {
+		if value == severity {
+			return i
+		}
+	}
+
+	return len(severities)
+}
+
+// markdownIndex creates the Markdown index of the report
+func markdownIndex(generated time.Time, summary []*reportSummary, findings []*reportFinding) string {
+	builder := &strings.Builder{}
+
+	builder.WriteString("# Nuclei Scan Report\n\n")
+	fmt.Fprintf(builder, "Generated on %s.\n\n", generated.Format(time.RFC1123))
+
+	builder.WriteString("## Summary\n\n| Severity | Findings |\n|---|---|\n")
+
+	for _, item := range summary {
+		fmt.Fprintf(builder, "| %s | %d |\n", item.Severity, item.Count)
+	}
+
+	builder.WriteString("\n## Findings\n\n")
+
+	if len(findings) == 0 {
+		builder.WriteString("No results found.\n")
+
+		return builder.String()
+	}
+
+	builder.WriteString("| # | Severity | Template | Matched |\n|---|---|---|---|\n")
+
+	for _, finding := range findings {
+		fmt.Fprintf(builder, "| [%s](%s) | %s | %s | %s |\n", finding.ID, finding.Page, finding.Severity,
+			markdownCell(finding.Template), markdownCell(finding.Matched))
+	}
+
+	return builder.String()
+}
+
+// markdownFinding creates the Markdown page of a finding
+func markdownFinding(finding *reportFinding) string {
+	builder := &strings.Builder{}
+
+	name := finding.Name
+	if name == "" {
+		name = finding.Template
+	}
+
+	fmt.Fprintf(builder, "# %s\n\n| Field | Value |\n|---|---|\n", name)
+
+	fields := [][2]string{
+		{"Template", finding.Template},
+		{"Author", finding.Author},
+		{"Severity", finding.Severity},
+		{"Type", finding.Type},
+		{"Host", finding.Host},
+		{"Matched", finding.Matched},
+		{"Matcher", finding.MatcherName},
+		{"Timestamp", finding.Timestamp.Format(time.RFC3339)},
+	}
+
+	for _, field := range fields {
+		if field[1] != "" {
+			fmt.Fprintf(builder, "| %s | %s |\n", field[0], markdownCell(field[1]))
+		}
+	}
+
+	if finding.Description != "" {
+		fmt.Fprintf(builder, "\n## Description\n\n%s\n", finding.Description)
+	}
+
+	if len(finding.ExtractedResults) > 0 {
+		builder.WriteString("\n## Extracted Results\n\n")
+
+		for _, result := range finding.ExtractedResults {
+			fmt.Fprintf(builder, "- `%s`\n", strings.ReplaceAll(result, "`", "'"))
+		}
+	}
+
+	if len(finding.Meta) > 0 {
+		builder.WriteString("\n## Payloads\n\n")
+
+		var metas []string
+		for name, value := range finding.Meta {
+			metas = append(metas, fmt.Sprintf("- %s: `%v`\n", name, value))
+		}
+
+		sort.Strings(metas)
+		builder.WriteString(strings.Join(metas, ""))
+	}
+
+	if finding.Request != "" {
+		fmt.Fprintf(builder, "\n## Request\n\n%s", markdownCode(finding.Request))
+	}
+
+	if finding.Response != "" {
+		fmt.Fprintf(builder, "\n## Response\n\n%s", markdownCode(finding.Response))
+	}
+
+	builder.WriteString("\n[Back to the report](../index.md)\n")
+
+	return builder.String()
+}
+
+// markdownCell escapes a value for a Markdown table cell
+func markdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+
+	return strings.ReplaceAll(value, "\n", " ")
+}
+
+// markdownCode creates a Markdown code block with a fence longer than any in the content
+func markdownCode(content string) string {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+
+	return fence + "\n" + strings.TrimRight(content, "\r\n") + "\n" + fence + "\n"
+}
+
+// htmlIndex is the self-contained HTML index of the report
+var htmlIndex = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Nuclei Scan Report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+pre { background: #f4f4f4; padding: 8px; overflow-x: auto; white-space: pre-wrap; word-break: break-all; }
+section { border-top: 1px solid #ccc; margin-top: 1.5em; }
+.critical { color: #fff; background: #7b1fa2; }
+.high { color: #fff; background: #d32f2f; }
+.medium { background: #f57c00; }
+.low { background: #fbc02d; }
+.info, .unknown { background: #90caf9; }
+.severity { padding: 2px 6px; border-radius: 3px; }
+</style>
+</head>
+<body>
+<h1>Nuclei Scan Report</h1>
+<p>Generated on {{.Generated}}.</p>
+<h2>Summary</h2>
+<table>
+<tr><th>Severity</th><th>Findings</th></tr>
+{{range .Summary}}<tr><td><span class="severity {{.Severity}}">{{.Severity}}</span></td><td>{{.Count}}</td></tr>
+{{end}}</table>
+<h2>Findings</h2>
+{{if .Findings}}<table>
+<tr><th>#</th><th>Severity</th><th>Template</th><th>Matched</th></tr>
+{{range .Findings}}<tr><td><a href="#finding-{{.ID}}">{{.ID}}</a></td><td><span class="severity {{.Severity}}">{{.Severity}}</span></td><td>{{.Template}}</td><td>{{.Matched}}</td></tr>
+{{end}}</table>
+{{range .Findings}}<section id="finding-{{.ID}}">
+<h3>{{.ID}}. {{if .Name}}{{.Name}}{{else}}{{.Template}}{{end}}</h3>
+<table>
+<tr><th>Template</th><td>{{.Template}}</td></tr>
+{{if .Author}}<tr><th>Author</th><td>{{.Author}}</td></tr>
+{{end}}<tr><th>Severity</th><td><span class="severity {{.Severity}}">{{.Severity}}</span></td></tr>
+<tr><th>Type</th><td>{{.Type}}</td></tr>
+<tr><th>Host</th><td>{{.Host}}</td></tr>
+<tr><th>Matched</th><td>{{.Matched}}</td></tr>
+{{if .MatcherName}}<tr><th>Matcher</th><td>{{.MatcherName}}</td></tr>
+{{end}}{{if .ExtractedResults}}<tr><th>Extracted Results</th><td>{{range .ExtractedResults}}<code>{{.}}</code><br>{{end}}</td></tr>
+{{end}}{{if .Meta}}<tr><th>Payloads</th><td>{{range $name, $value := .Meta}}{{$name}}: <code>{{$value}}</code><br>{{end}}</td></tr>
+{{end}}<tr><th>Timestamp</th><td>{{.Timestamp.Format "2006-01-02T15:04:05Z07:00"}}</td></tr>
+</table>
+{{if .Description}}<p>{{.Description}}</p>
+{{end}}{{if .Request}}<h4>Request</h4>
+<pre>{{.Request}}</pre>
+{{end}}{{if .Response}}<h4>Response</h4>
+<pre>{{.Response}}</pre>
+{{end}}</section>
+{{end}}{{else}}<p>No results found.</p>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,62 @@
+package reporting
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/executer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDirectory(t *testing.T) {
+	directory, err := ioutil.TempDir("", "nuclei-report-")
+	require.Nil(t, err, "Could not create temporary directory")
+
+	defer os.RemoveAll(directory)
+
+	writer := NewReportWriter()
+
+	events := []*executer.ResultEvent{
+		{Template: "info-page", Info: templates.Info{Severity: "info"}, Type: "http", Matched: "https://example.com/info"},
+		{Template: "admin-panel", Info: templates.Info{Name: "Admin Panel", Severity: "High"}, Type: "http", Matched: "https://example.com/admin",
+			Request: "GET /admin HTTP/1.1\r\nHost: example.com\r\n\r\n", Response: "HTTP/1.1 200 OK\r\n\r\n<title>Admin</title>"},
+		{Template: "debug-page", Info: templates.Info{Severity: "high"}, Type: "http", Matched: "https://example.com/debug"},
+		{Template: "odd-severity", Info: templates.Info{Severity: "urgent"}, Type: "dns", Matched: "example.com"},
+	}
+
+	for _, event := range events {
+		require.Nil(t, writer.Write(event), "Could not write event")
+	}
+
+	err = writer.WriteDirectory(directory)
+	require.Nil(t, err, "Could not write report")
+
+	index, err := ioutil.ReadFile(filepath.Join(directory, "index.md"))
+	require.Nil(t, err, "Could not read markdown index")
+
+	for _, line := range []string{"| critical | 0 |\n", "| high | 2 |\n", "| medium | 0 |\n", "| low | 0 |\n", "| info | 1 |\n", "| unknown | 1 |\n"} {
+		require.Contains(t, string(index), line, "Could not count findings per severity")
+	}
+
+	// The findings are sorted by severity, then template
+	require.Contains(t, string(index), "| [0001](findings/0001-admin-panel.md) | high | admin-panel | https://example.com/admin |\n", "Could not list first finding")
+	require.Contains(t, string(index), "| [0004](findings/0004-odd-severity.md) | unknown | odd-severity | example.com |\n", "Could not list unknown severity last")
+
+	page, err := ioutil.ReadFile(filepath.Join(directory, "findings", "0001-admin-panel.md"))
+	require.Nil(t, err, "Could not read finding page")
+	require.Contains(t, string(page), "# Admin Panel\n", "Could not write finding name")
+	require.Contains(t, string(page), "## Request\n\n```\nGET /admin HTTP/1.1\r\nHost: example.com\n```\n", "Could not write dumped request")
+	require.Contains(t, string(page), "## Response\n\n```\nHTTP/1.1 200 OK\r\n\r\n<title>Admin</title>\n```\n", "Could not write dumped response")
+
+	page, err = ioutil.ReadFile(filepath.Join(directory, "findings", "0003-info-page.md"))
+	require.Nil(t, err, "Could not read finding page without request")
+	require.NotContains(t, string(page), "## Request", "Could write request of finding without request")
+
+	html, err := ioutil.ReadFile(filepath.Join(directory, "index.html"))
+	require.Nil(t, err, "Could not read html index")
+	require.Contains(t, string(html), `<td><span class="severity high">high</span></td><td>2</td>`, "Could not count findings in html index")
+	require.Contains(t, string(html), "&lt;title&gt;Admin&lt;/title&gt;", "Could not escape response in html index")
+}
@@ -0,0 +1,300 @@
+package reporting
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/projectdiscovery/nuclei/v2/pkg/executer"
+)
+
+const (
+	sarifSchema  = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolURI      = "https://github.com/projectdiscovery/nuclei"
+)
+
+// SarifWriter collects the results of a scan and writes them as a SARIF report.
+// Each template is a rule of the report, and each result found by a template
+// is a result of that rule located at the matched URL.
+type SarifWriter struct {
+	version string
+	rules   []*sarifRule
+	ruleIDs map[string]int
+	results []*sarifResult
+	mutex   sync.Mutex
+}
+
+type sarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool      `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string       `json:"name"`
+	Version        string       `json:"version,omitempty"`
+	InformationURI string       `json:"informationUri,omitempty"`
+	Rules          []*sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name,omitempty"`
+	ShortDescription     *sarifMessage          `json:"shortDescription,omitempty"`
+	FullDescription      *sarifMessage          `json:"fullDescription,omitempty"`
+	DefaultConfiguration sarifConfiguration     `json:"defaultConfiguration"`
+	Properties           map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string                 `json:"ruleId"`
+	RuleIndex  int                    `json:"ruleIndex"`
+	Level      string                 `json:"level"`
+	Message    sarifMessage           `json:"message"`
+	Locations  []*sarifLocation       `json:"locations"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation  `json:"physicalLocation,omitempty"`
+	LogicalLocations []*sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// NewSarifWriter creates a new SARIF writer. The version is
+// the version of nuclei reported as the tool of the run.
+func NewSarifWriter(version string) *SarifWriter {
+	return &SarifWriter{version: version, ruleIDs: make(map[string]int)}
+}
+
+// Write adds a result event to the report
+func (w *SarifWriter) Write(event *executer.ResultEvent) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	index, ok := w.ruleIDs[event.Template]
+	if !ok {
+		index = len(w.rules)
+		w.ruleIDs[event.Template] = index
+		w.rules = append(w.rules, newSarifRule(event))
+	}
+
+	result := &sarifResult{
+		RuleID:     event.Template,
+		RuleIndex:  index,
+		Level:      sarifLevel(event.Severity),
+		Message:    sarifMessage{Text: fmt.Sprintf("%s matched at %s", ruleName(event), event.Matched)},
+		Locations:  []*sarifLocation{newSarifLocation(event)},
+		Properties: make(map[string]interface{}),
+	}
+
+	result.Properties["type"] = event.Type
+	result.Properties["host"] = event.Host
+
+	if event.MatcherName != "" {
+		result.Properties["matcher-name"] = event.MatcherName
+	}
+
+	if len(event.ExtractedResults) > 0 {
+		result.Properties["extracted-results"] = event.ExtractedResults
+	}
+
+	w.results = append(w.results, result)
+
+	return nil
+}
+
+// Close does nothing as the report is written with WriteFile
+func (w *SarifWriter) Close() error {
+	return nil
+}
+
+// WriteFile writes the SARIF report with the results collected so far to a file
+func (w *SarifWriter) WriteFile(path string) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	rules := w.rules
+	if rules == nil {
+		rules = []*sarifRule{}
+	}
+
+	results := w.results
+	if results == nil {
+		results = []*sarifResult{}
+	}
+
+	log := &sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []*sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "nuclei",
+				Version:        w.version,
+				InformationURI: toolURI,
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+
+	data, err := jsoniter.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// newSarifRule creates the rule for the template of a result event
+func newSarifRule(event *executer.ResultEvent) *sarifRule {
+	rule := &sarifRule{
+		ID:                   event.Template,
+		Name:                 event.Name,
+		ShortDescription:     &sarifMessage{Text: ruleName(event)},
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevel(event.Severity)},
+		Properties:           make(map[string]interface{}),
+	}
+
+	if event.Description != "" {
+		rule.FullDescription = &sarifMessage{Text: event.Description}
+	}
+
+	if event.Severity != "" {
+		rule.Properties["severity"] = event.Severity
+	}
+
+	if score, ok := securitySeverity[strings.ToLower(event.Severity)]; ok {
+		rule.Properties["security-severity"] = score
+	}
+
+	if event.Author != "" {
+		rule.Properties["author"] = event.Author
+	}
+
+	return rule
+}
+
+// newSarifLocation creates the location of a result event. Files are located by their
+// file uri and matched line, and urls by themselves. The domains of dns results and the
+// addresses of network and ssl results are not artifacts, they are logical locations.
+func newSarifLocation(event *executer.ResultEvent) *sarifLocation {
+	switch event.Type {
+	case "file":
+		path, line := splitFileLocation(event.Matched)
+
+		location := &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: fileURI(path)}}
+		if line > 0 {
+			location.Region = &sarifRegion{StartLine: line}
+		}
+
+		return &sarifLocation{PhysicalLocation: location}
+	case "dns", "network", "ssl":
+		return &sarifLocation{LogicalLocations: []*sarifLogicalLocation{{Name: event.Matched, Kind: "resource"}}}
+	}
+
+	return &sarifLocation{PhysicalLocation: &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: event.Matched}}}
+}
+
+// splitFileLocation splits a file location written as path:line into the path and
+// the line number. The line number is 0 if the location has none.
+func splitFileLocation(location string) (string, int) {
+	index := strings.LastIndex(location, ":")
+	if index < 0 {
+		return location, 0
+	}
+
+	line, err := strconv.Atoi(location[index+1:])
+	if err != nil || line <= 0 {
+		return location, 0
+	}
+
+	return location[:index], line
+}
+
+// fileURI returns the file uri of a path, made absolute if possible
+func fileURI(path string) string {
+	if absolute, err := filepath.Abs(path); err == nil {
+		path = absolute
+	}
+
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// Windows paths like C:/dir get an empty authority
+		path = "/" + path
+	}
+
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// ruleName returns the name of the template of a result event, or its ID if unnamed
+func ruleName(event *executer.ResultEvent) string {
+	if event.Name != "" {
+		return event.Name
+	}
+
+	return event.Template
+}
+
+// securitySeverity contains the scores used by code scanning dashboards to rank the severities
+var securitySeverity = map[string]string{
+	"critical": "9.0",
+	"high":     "7.0",
+	"medium":   "5.0",
+	"low":      "3.0",
+	"info":     "0.0",
+}
+
+// sarifLevel converts a template severity to a SARIF level
+func sarifLevel(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical", "high":
+		return "error"
+	case "medium":
+		return "warning"
+	case "low", "info":
+		return "note"
+	default:
+		return "none"
+	}
+}
@@ -0,0 +1,28 @@
+package reporting
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/executer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSarifLocations(t *testing.T) {
+	location := newSarifLocation(&executer.ResultEvent{Type: "file", Matched: "/src/my app/config.php:12"})
+	require.NotNil(t, location.PhysicalLocation, "Could not locate file result")
+	require.Equal(t, "file:///src/my%20app/config.php", location.PhysicalLocation.ArtifactLocation.URI, "Could not make file uri")
+	require.Equal(t, &sarifRegion{StartLine: 12}, location.PhysicalLocation.Region, "Could not set matched line")
+
+	location = newSarifLocation(&executer.ResultEvent{Type: "file", Matched: "/src/config.php"})
+	require.Equal(t, "file:///src/config.php", location.PhysicalLocation.ArtifactLocation.URI, "Could not make file uri without line")
+	require.Nil(t, location.PhysicalLocation.Region, "Could set line of file without matched line")
+
+	location = newSarifLocation(&executer.ResultEvent{Type: "http", Matched: "https://example.com/admin"})
+	require.Equal(t, "https://example.com/admin", location.PhysicalLocation.ArtifactLocation.URI, "Could not locate http result")
+
+	for _, requestType := range []string{"dns", "network", "ssl"} {
+		location = newSarifLocation(&executer.ResultEvent{Type: requestType, Matched: "example.com:443"})
+		require.Nil(t, location.PhysicalLocation, "Could locate %s result as artifact", requestType)
+		require.Equal(t, []*sarifLogicalLocation{{Name: "example.com:443", Kind: "resource"}}, location.LogicalLocations, "Could not locate %s result", requestType)
+	}
+}
@@ -2,15 +2,15 @@ package requests
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"regexp"
 	"strings"
+	"sync/atomic"
 
-	"github.com/Knetic/govaluate"
 	"github.com/projectdiscovery/nuclei/v2/pkg/extractors"
 	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
 	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
@@ -20,6 +20,9 @@ import (
 const (
 	two   = 2
 	three = 3
+
+	defaultPipelineDepth = 10
+	defaultRaceCount     = 10
 )
 
 // BulkHTTPRequest contains a request to be made from a template
@@ -44,6 +47,14 @@ type BulkHTTPRequest struct {
 	Headers map[string]string `yaml:"headers,omitempty"`
 	// Body is an optional parameter which contains the request body for POST methods, etc
 	Body string `yaml:"body,omitempty"`
+	// BodyFile is a file relative to the template whose content is sent as the body
+	BodyFile string `yaml:"body-file,omitempty"`
+	// bodyFile contains the content of the body file
+	bodyFile []byte
+	// Multipart contains the fields of a multipart/form-data body
+	Multipart []*MultipartField `yaml:"multipart,omitempty"`
+	// Form contains the fields of an application/x-www-form-urlencoded body
+	Form map[string]string `yaml:"form,omitempty"`
 	// Matchers contains the detection mechanism for the request to identify
 	// whether the request was successful
 	Matchers []*matchers.Matcher `yaml:"matchers,omitempty"`
@@ -57,9 +68,32 @@ type BulkHTTPRequest struct {
 	Extractors []*extractors.Extractor `yaml:"extractors,omitempty"`
 	// MaxRedirects is the maximum number of redirects that should be followed.
 	MaxRedirects int `yaml:"max-redirects,omitempty"`
+	// ConnectionClose sends the Connection: close header so that the connection
+	// is closed after each request, even when keep-alive is enabled.
+	ConnectionClose bool `yaml:"connection-close,omitempty"`
+	// Pipeline sends the requests of the block over a single connection without waiting
+	// for the responses, for servers supporting HTTP pipelining. The requests are built
+	// before any is sent, so values extracted by the block aren't available to them.
+	Pipeline bool `yaml:"pipeline,omitempty"`
+	// PipelineDepth is the number of requests written on the connection before
+	// reading their responses when pipelining. Default is 10.
+	PipelineDepth int `yaml:"pipeline-depth,omitempty"`
+	// Race sends each request of the block over race_count connections at the same time
+	// to test for race conditions. The matchers are run on the whole set of responses,
+	// each one having to match at least min-matches responses.
+	Race bool `yaml:"race,omitempty"`
+	// RaceCount is the number of requests sent at the same time in race mode. Default is 10.
+	RaceCount int `yaml:"race_count,omitempty"`
+	// Unsafe writes the raw requests on the connection exactly as written once the
+	// variables are replaced, and reads the responses leniently. The requests can then
+	// be malformed, e.g. to test for request smuggling. Line endings are sent as written
+	// and no trailing line is added, so use a |+ block to end the headers.
+	Unsafe bool `yaml:"unsafe,omitempty"`
 	// Raw contains raw requests
 	Raw  []string `yaml:"raw,omitempty"`
 	gsfm *GeneratorFSM
+	// generators is the number of generators created, used to give each one a unique key
+	generators uint64
 }
 
 // GetMatchersCondition returns the condition for the matcher
@@ -82,12 +116,50 @@ func (r *BulkHTTPRequest) SetAttackType(attack generators.Type) {
 	r.attackType = attack
 }
 
+// GetPipelineDepth returns the number of requests to write at once when pipelining
+func (r *BulkHTTPRequest) GetPipelineDepth() int {
+	if r.PipelineDepth <= 0 {
+		return defaultPipelineDepth
+	}
+
+	return r.PipelineDepth
+}
+
+// GetRaceCount returns the number of requests to send at the same time in race mode
+func (r *BulkHTTPRequest) GetRaceCount() int {
+	if r.RaceCount <= 0 {
+		return defaultRaceCount
+	}
+
+	return r.RaceCount
+}
+
 // Returns the total number of requests the YAML rule will perform
 func (r *BulkHTTPRequest) GetRequestCount() int64 {
 	return int64(len(r.Raw) | len(r.Path))
 }
 
-func (r *BulkHTTPRequest) MakeHTTPRequest(ctx context.Context, baseURL string, dynamicValues map[string]interface{}, data string) (*HTTPRequest, error) {
+// Validate checks the expressions of the templated fields of the request
+func (r *BulkHTTPRequest) Validate() error {
+	fields := append(append([]string{r.Body}, r.Path...), r.Raw...)
+
+	for _, value := range r.Headers {
+		fields = append(fields, value)
+	}
+
+	for key, value := range r.Form {
+		fields = append(fields, key, value)
+	}
+
+	for _, field := range r.Multipart {
+		fields = append(fields, field.Name, field.Filename, field.ContentType, field.Value)
+	}
+
+	return validateExpressions(fields...)
+}
+
+// MakeHTTPRequest creates a request for a URL from the current path or raw request of a generator
+func (r *BulkHTTPRequest) MakeHTTPRequest(ctx context.Context, baseURL, generator string, dynamicValues map[string]interface{}, data string) (*HTTPRequest, error) {
 	parsed, err := url.Parse(baseURL)
 	if err != nil {
 		return nil, err
@@ -102,7 +174,7 @@ func (r *BulkHTTPRequest) MakeHTTPRequest(ctx context.Context, baseURL string, d
 
 	// if data contains \n it's a raw request
 	if strings.Contains(data, "\n") {
-		return r.makeHTTPRequestFromRaw(ctx, baseURL, data, values)
+		return r.makeHTTPRequestFromRaw(ctx, baseURL, generator, data, values)
 	}
 
 	return r.makeHTTPRequestFromModel(ctx, data, values)
@@ -110,8 +182,10 @@ func (r *BulkHTTPRequest) MakeHTTPRequest(ctx context.Context, baseURL string, d
 
 // MakeHTTPRequestFromModel creates a *http.Request from a request template
 func (r *BulkHTTPRequest) makeHTTPRequestFromModel(ctx context.Context, data string, values map[string]interface{}) (*HTTPRequest, error) {
-	replacer := newReplacer(values)
-	URL := replacer.Replace(data)
+	URL, err := evaluate(data, values)
+	if err != nil {
+		return nil, err
+	}
 
 	// Build a request on the specified URL
 	req, err := http.NewRequestWithContext(ctx, r.Method, URL, nil)
@@ -131,28 +205,35 @@ func (r *BulkHTTPRequest) InitGenerator() {
 	r.gsfm = NewGeneratorFSM(r.attackType, r.Payloads, r.Path, r.Raw)
 }
 
-func (r *BulkHTTPRequest) CreateGenerator(reqURL string) {
-	r.gsfm.Add(reqURL)
+// CreateGenerator creates a generator of the requests for a URL, returning its key. Each
+// call has its own generator so that the same URL can be scanned several times at once.
+func (r *BulkHTTPRequest) CreateGenerator(reqURL string) string {
+	generator := fmt.Sprintf("%s#%d", reqURL, atomic.AddUint64(&r.generators, 1))
+	r.gsfm.Add(generator)
+
+	return generator
 }
 
-func (r *BulkHTTPRequest) HasGenerator(reqURL string) bool {
-	return r.gsfm.Has(reqURL)
+func (r *BulkHTTPRequest) DeleteGenerator(generator string) {
+	r.gsfm.Delete(generator)
 }
 
-func (r *BulkHTTPRequest) ReadOne(reqURL string) {
-	r.gsfm.ReadOne(reqURL)
+func (r *BulkHTTPRequest) ReadOne(generator string) {
+	r.gsfm.ReadOne(generator)
 }
 
 // makeHTTPRequestFromRaw creates a *http.Request from a raw request
-func (r *BulkHTTPRequest) makeHTTPRequestFromRaw(ctx context.Context, baseURL, data string, values map[string]interface{}) (*HTTPRequest, error) {
-	// Add trailing line
-	data += "\n"
+func (r *BulkHTTPRequest) makeHTTPRequestFromRaw(ctx context.Context, baseURL, generator, data string, values map[string]interface{}) (*HTTPRequest, error) {
+	// Add trailing line, unsafe requests are sent as written
+	if !r.Unsafe {
+		data += "\n"
+	}
 
 	if len(r.Payloads) > 0 {
-		r.gsfm.InitOrSkip(baseURL)
-		r.ReadOne(baseURL)
+		r.gsfm.InitOrSkip(generator)
+		r.ReadOne(generator)
 
-		return r.handleRawWithPaylods(ctx, data, baseURL, values, r.gsfm.Value(baseURL))
+		return r.handleRawWithPaylods(ctx, data, baseURL, values, r.gsfm.Value(generator))
 	}
 
 	// otherwise continue with normal flow
@@ -163,34 +244,15 @@ func (r *BulkHTTPRequest) handleRawWithPaylods(ctx context.Context, raw, baseURL
 	baseValues := generators.CopyMap(values)
 	finValues := generators.MergeMaps(baseValues, genValues)
 
-	replacer := newReplacer(finValues)
-
-	// Replace the dynamic variables in the URL if any
-	raw = replacer.Replace(raw)
-
-	dynamicValues := make(map[string]interface{})
-	// find all potentials tokens between {{}}
-	var re = regexp.MustCompile(`(?m)\{\{.+}}`)
-	for _, match := range re.FindAllString(raw, -1) {
-		// check if the match contains a dynamic variable
-		expr := generators.TrimDelimiters(match)
-		compiled, err := govaluate.NewEvaluableExpressionWithFunctions(expr, generators.HelperFunctions())
-
-		if err != nil {
-			return nil, err
-		}
-
-		result, err := compiled.Evaluate(finValues)
-		if err != nil {
-			return nil, err
-		}
-
-		dynamicValues[expr] = result
+	// Replace the variables and evaluate the expressions of the request
+	raw, err := evaluate(raw, finValues)
+	if err != nil {
+		return nil, err
 	}
 
-	// replace dynamic values
-	dynamicReplacer := newReplacer(dynamicValues)
-	raw = dynamicReplacer.Replace(raw)
+	if r.Unsafe {
+		return r.makeUnsafeRequest(raw, baseURL, genValues)
+	}
 
 	compiledRequest, err := r.parseRawRequest(raw, baseURL)
 	if err != nil {
@@ -216,18 +278,34 @@ func (r *BulkHTTPRequest) handleRawWithPaylods(ctx context.Context, raw, baseURL
 }
 
 func (r *BulkHTTPRequest) fillRequest(req *http.Request, values map[string]interface{}) (*retryablehttp.Request, error) {
-	req.Header.Set("Connection", "close")
-	req.Close = true
-	replacer := newReplacer(values)
+	if r.ConnectionClose {
+		req.Header.Set("Connection", "close")
+		req.Close = true
+	}
 
 	// Check if the user requested a request body
-	if r.Body != "" {
-		req.Body = ioutil.NopCloser(strings.NewReader(r.Body))
+	body, contentType, err := r.makeBody(values)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
 	}
 
 	// Set the header values requested
 	for header, value := range r.Headers {
-		req.Header[header] = []string{replacer.Replace(value)}
+		value, err := evaluate(value, values)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header[header] = []string{value}
+	}
+
+	// The boundary of a multipart body must match the one of the header
+	if _, ok := req.Header["Content-Type"]; contentType != "" && (!ok || len(r.Multipart) > 0) {
+		req.Header.Set("Content-Type", contentType)
 	}
 
 	// Set some headers only if the header wasn't supplied by the user
@@ -254,6 +332,42 @@ func (r *BulkHTTPRequest) fillRequest(req *http.Request, values map[string]inter
 type HTTPRequest struct {
 	Request *retryablehttp.Request
 	Meta    map[string]interface{}
+	// Unsafe is the request to send as is in unsafe mode, Request is nil then
+	Unsafe *UnsafeRequest
+}
+
+// UnsafeRequest is a raw request sent exactly as written
+type UnsafeRequest struct {
+	// Method is the method of the request line, needed to read the response
+	Method string
+	// URL is the URL the request is sent for
+	URL string
+	// Data contains the bytes to write on the connection
+	Data []byte
+}
+
+// makeUnsafeRequest creates a request sending the raw data as is. The request line
+// is only used to report the URL and may be malformed.
+func (r *BulkHTTPRequest) makeUnsafeRequest(raw, baseURL string, genValues map[string]interface{}) (*HTTPRequest, error) {
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse request URL: %s", err)
+	}
+
+	unsafeRequest := &UnsafeRequest{URL: baseURL, Data: []byte(raw)}
+
+	requestLine := strings.TrimSpace(strings.SplitN(raw, "\n", two)[0])
+
+	parts := strings.Split(requestLine, " ")
+	if len(parts) > 0 {
+		unsafeRequest.Method = parts[0]
+	}
+
+	if len(parts) > 1 && strings.HasPrefix(parts[1], "/") {
+		unsafeRequest.URL = fmt.Sprintf("%s://%s%s", parsedURL.Scheme, parsedURL.Host, parts[1])
+	}
+
+	return &HTTPRequest{Unsafe: unsafeRequest, Meta: genValues}, nil
 }
 
 // CustomHeaders valid for all requests
@@ -369,25 +483,25 @@ func (r *BulkHTTPRequest) parseRawRequest(request, baseURL string) (*RawRequest,
 	return &rawRequest, nil
 }
 
-func (r *BulkHTTPRequest) Next(reqURL string) bool {
-	return r.gsfm.Next(reqURL)
+func (r *BulkHTTPRequest) Next(generator string) bool {
+	return r.gsfm.Next(generator)
 }
-func (r *BulkHTTPRequest) Position(reqURL string) int {
-	return r.gsfm.Position(reqURL)
+func (r *BulkHTTPRequest) Position(generator string) int {
+	return r.gsfm.Position(generator)
 }
 
-func (r *BulkHTTPRequest) Reset(reqURL string) {
-	r.gsfm.Reset(reqURL)
+func (r *BulkHTTPRequest) Reset(generator string) {
+	r.gsfm.Reset(generator)
 }
 
-func (r *BulkHTTPRequest) Current(reqURL string) string {
-	return r.gsfm.Current(reqURL)
+func (r *BulkHTTPRequest) Current(generator string) string {
+	return r.gsfm.Current(generator)
 }
 
 func (r *BulkHTTPRequest) Total() int {
 	return len(r.Path) + len(r.Raw)
 }
 
-func (r *BulkHTTPRequest) Increment(reqURL string) {
-	r.gsfm.Increment(reqURL)
+func (r *BulkHTTPRequest) Increment(generator string) {
+	r.gsfm.Increment(generator)
 }
@@ -1,10 +1,12 @@
 package requests
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/miekg/dns"
 	"github.com/projectdiscovery/nuclei/v2/pkg/extractors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
 	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
 )
 
@@ -16,6 +18,12 @@ type DNSRequest struct {
 	Type    string `yaml:"type"`
 	Class   string `yaml:"class"`
 	Retries int    `yaml:"retries"`
+	// Transport is the transport of the query, udp or tcp. Default is udp.
+	Transport string `yaml:"transport,omitempty"`
+	// Authoritative sends the query directly to the authoritative nameservers
+	// of the name instead of the resolvers. Zone transfers are always sent to
+	// the authoritative nameservers over tcp.
+	Authoritative bool `yaml:"authoritative,omitempty"`
 	// Raw contains a raw request
 	Raw string `yaml:"raw,omitempty"`
 
@@ -47,8 +55,51 @@ func (r *DNSRequest) GetRequestCount() int64 {
 	return 1
 }
 
-// MakeDNSRequest creates a *dns.Request from a request template
-func (r *DNSRequest) MakeDNSRequest(domain string) (*dns.Msg, error) {
+// GetRetries returns the number of attempts to make for the request, at least one
+func (r *DNSRequest) GetRetries() int {
+	if r.Retries < 1 {
+		return 1
+	}
+
+	return r.Retries
+}
+
+// Validate checks the type and the transport of the request and the
+// expressions of its templated fields.
+func (r *DNSRequest) Validate() error {
+	if _, err := toQType(r.Type); err != nil {
+		return err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(r.Transport)) {
+	case "", "udp", "tcp":
+	default:
+		return fmt.Errorf("unknown dns transport: %s", r.Transport)
+	}
+
+	return validateExpressions(r.Name)
+}
+
+// IsZoneTransfer returns true if the request is a zone transfer
+func (r *DNSRequest) IsZoneTransfer() bool {
+	qtype, _ := toQType(r.Type)
+
+	return qtype == dns.TypeAXFR
+}
+
+// IsTCP returns true if the request must be sent over tcp
+func (r *DNSRequest) IsTCP() bool {
+	return strings.EqualFold(strings.TrimSpace(r.Transport), "tcp") || r.IsZoneTransfer()
+}
+
+// IsAuthoritative returns true if the request must be sent to the authoritative nameservers of the name
+func (r *DNSRequest) IsAuthoritative() bool {
+	return r.Authoritative || r.IsZoneTransfer()
+}
+
+// MakeDNSRequest creates a *dns.Request from a request template. The values
+// are variables extracted by previous requests of the template, if any.
+func (r *DNSRequest) MakeDNSRequest(domain string, values map[string]interface{}) (*dns.Msg, error) {
 	domain = dns.Fqdn(domain)
 
 	// Build a request on the specified URL
@@ -58,42 +109,37 @@ func (r *DNSRequest) MakeDNSRequest(domain string) (*dns.Msg, error) {
 
 	var q dns.Question
 
-	replacer := newReplacer(map[string]interface{}{"FQDN": domain})
+	name, err := evaluate(r.Name, generators.MergeMaps(values, map[string]interface{}{"FQDN": domain}))
+	if err != nil {
+		return nil, err
+	}
 
-	q.Name = dns.Fqdn(replacer.Replace(r.Name))
+	q.Name = dns.Fqdn(name)
 	q.Qclass = toQClass(r.Class)
-	q.Qtype = toQType(r.Type)
+
+	q.Qtype, err = toQType(r.Type)
+	if err != nil {
+		return nil, err
+	}
 
 	req.Question = append(req.Question, q)
 
 	return req, nil
 }
 
-func toQType(ttype string) (rtype uint16) {
+// toQType converts a query type name like SRV or AXFR to its value. A is used by default.
+func toQType(ttype string) (uint16, error) {
 	ttype = strings.TrimSpace(strings.ToUpper(ttype))
+	if ttype == "" {
+		return dns.TypeA, nil
+	}
 
-	switch ttype {
-	case "A":
-		rtype = dns.TypeA
-	case "NS":
-		rtype = dns.TypeNS
-	case "CNAME":
-		rtype = dns.TypeCNAME
-	case "SOA":
-		rtype = dns.TypeSOA
-	case "PTR":
-		rtype = dns.TypePTR
-	case "MX":
-		rtype = dns.TypeMX
-	case "TXT":
-		rtype = dns.TypeTXT
-	case "AAAA":
-		rtype = dns.TypeAAAA
-	default:
-		rtype = dns.TypeA
+	rtype, ok := dns.StringToType[ttype]
+	if !ok {
+		return 0, fmt.Errorf("unknown dns query type: %s", ttype)
 	}
 
-	return
+	return rtype, nil
 }
 
 func toQClass(tclass string) (rclass uint16) {
@@ -0,0 +1,216 @@
+package requests
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Knetic/govaluate"
+	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+)
+
+// evaluate evaluates the {{...}} expressions of a templated field with the values as variables
+// and the helper functions, and replaces the variables written without delimiters in the rest
+// of the field. Only the expressions using variables or helper functions are evaluated, the
+// others being sent as written, as they are often payloads like {{7*7}} for template
+// injections. An expression preceded by a backslash, like \{{BaseURL}}, is sent as written.
+func evaluate(data string, values map[string]interface{}) (string, error) {
+	if !strings.Contains(data, "{{") {
+		return newReplacer(values).Replace(data), nil
+	}
+
+	replacer := newReplacer(values)
+	builder := &strings.Builder{}
+	last := 0
+
+	for _, location := range findExpressions(data) {
+		text, expression := data[last:location[0]], data[location[0]:location[1]]
+		last = location[1]
+
+		if strings.HasSuffix(text, "\\") {
+			builder.WriteString(replacer.Replace(text[:len(text)-1]))
+			builder.WriteString(expression)
+
+			continue
+		}
+
+		builder.WriteString(replacer.Replace(text))
+
+		result, ok, err := evaluateExpression(data[location[2]:location[3]], values)
+		if err != nil {
+			return "", fmt.Errorf("could not evaluate expression %s: %s", expression, err)
+		}
+
+		if !ok {
+			result = expression
+		}
+
+		builder.WriteString(result)
+	}
+
+	builder.WriteString(replacer.Replace(data[last:]))
+
+	return builder.String(), nil
+}
+
+// findExpressions returns the locations of the {{...}} expressions of a templated field, each
+// as the start and the end of the expression followed by the start and the end of its content.
+// The expressions end at the first }} which is not in a quoted literal, so that a helper call
+// like {{contains(body, "}}")}} is one expression, and don't span several lines.
+func findExpressions(data string) [][]int {
+	var locations [][]int
+
+	for start := 0; ; {
+		index := strings.Index(data[start:], "{{")
+		if index < 0 {
+			return locations
+		}
+
+		open := start + index
+
+		end := closingDelimiter(data, open+2)
+		if end < 0 {
+			start = open + 1
+			continue
+		}
+
+		locations = append(locations, []int{open, end + 2, open + 2, end})
+		start = end + 2
+	}
+}
+
+// closingDelimiter returns the index of the }} closing the expression whose content begins
+// at start, or -1 if there is none on the line. The content of an expression is not empty.
+// If a literal is not terminated on the line, the expression ends at the first }} instead.
+func closingDelimiter(data string, start int) int {
+	var quote byte
+
+	for i := start; i < len(data) && data[i] != '\n'; i++ {
+		switch {
+		case quote != 0 && data[i] == '\\':
+			// Skip the escaped character
+			i++
+		case quote != 0:
+			if data[i] == quote {
+				quote = 0
+			}
+		case data[i] == '"' || data[i] == '\'':
+			quote = data[i]
+		case i > start && strings.HasPrefix(data[i:], "}}"):
+			return i
+		}
+	}
+
+	line := data[start:]
+	if index := strings.IndexByte(line, '\n'); index >= 0 {
+		line = line[:index]
+	}
+
+	if len(line) < 3 {
+		return -1
+	}
+
+	index := strings.Index(line[1:], "}}")
+	if index < 0 {
+		return -1
+	}
+
+	return start + 1 + index
+}
+
+// evaluateExpression evaluates an expression. It returns false if the expression is not one to
+// evaluate: it doesn't compile, it uses variables without a value, or it uses neither variables
+// nor helper functions.
+func evaluateExpression(expression string, values map[string]interface{}) (string, bool, error) {
+	// Plain variables don't need to be compiled
+	if value, ok := values[expression]; ok {
+		return expressionToString(value), true, nil
+	}
+
+	compiled, err := govaluate.NewEvaluableExpressionWithFunctions(expression, generators.HelperFunctions())
+	if err != nil || !usesVariablesOrFunctions(compiled) {
+		return "", false, nil
+	}
+
+	for _, name := range compiled.Vars() {
+		if _, ok := values[name]; !ok {
+			return "", false, nil
+		}
+	}
+
+	value, err := compiled.Evaluate(values)
+	if err != nil {
+		return "", false, err
+	}
+
+	return expressionToString(value), true, nil
+}
+
+// usesVariablesOrFunctions returns true if a compiled expression uses variables or helper functions
+func usesVariablesOrFunctions(compiled *govaluate.EvaluableExpression) bool {
+	if len(compiled.Vars()) > 0 {
+		return true
+	}
+
+	for _, token := range compiled.Tokens() {
+		if token.Kind == govaluate.FUNCTION {
+			return true
+		}
+	}
+
+	return false
+}
+
+// evaluateAll evaluates several templated fields at once
+func evaluateAll(values map[string]interface{}, fields ...string) ([]string, error) {
+	results := make([]string, len(fields))
+
+	for i, field := range fields {
+		result, err := evaluate(field, values)
+		if err != nil {
+			return nil, err
+		}
+
+		results[i] = result
+	}
+
+	return results, nil
+}
+
+// expressionToString formats the value of an expression, without
+// decimals for the numbers that are integers
+func expressionToString(value interface{}) string {
+	if number, ok := value.(float64); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// validateExpressions checks that the {{...}} expressions of templated fields calling
+// helper functions compile, so that syntax errors are reported when loading templates.
+// Other expressions which don't compile are sent as written.
+func validateExpressions(fields ...string) error {
+	functions := generators.HelperFunctions()
+
+	for _, field := range fields {
+		for _, location := range findExpressions(field) {
+			expression := strings.TrimSpace(field[location[2]:location[3]])
+
+			name := expression
+			if index := strings.IndexByte(expression, '('); index > 0 {
+				name = strings.TrimSpace(expression[:index])
+			}
+
+			if _, ok := functions[name]; !ok {
+				continue
+			}
+
+			if _, err := govaluate.NewEvaluableExpressionWithFunctions(expression, functions); err != nil {
+				return fmt.Errorf("invalid expression %s: %s", field[location[0]:location[1]], err)
+			}
+		}
+	}
+
+	return nil
+}
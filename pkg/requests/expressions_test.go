@@ -0,0 +1,76 @@
+package requests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	values := map[string]interface{}{"BaseURL": "http://127.0.0.1:8080", "Hostname": "127.0.0.1:8080"}
+
+	tests := []struct {
+		data     string
+		expected string
+	}{
+		{"{{BaseURL}}/admin", "http://127.0.0.1:8080/admin"},
+		{"{{md5(Hostname)}}", "5958c386bf5e9109ac10d2a628645aea"},
+		{"{{toupper('abc')}}", "ABC"},
+		{"{{unknown}}", "{{unknown}}"},
+		{"{{md5(unknown)}}", "{{md5(unknown)}}"},
+		{"{{7*7}}", "{{7*7}}"},
+		{"{{config.items()}}", "{{config.items()}}"},
+		{"{{constructor.constructor('alert(1)')()}}", "{{constructor.constructor('alert(1)')()}}"},
+		{`\{{BaseURL}}/{{Hostname}}`, "{{BaseURL}}/127.0.0.1:8080"},
+		{"{{Hostname}}{{Hostname}}", "127.0.0.1:8080127.0.0.1:8080"},
+		{`{{contains(BaseURL, "}}")}}`, "false"},
+		{`{{toupper('a}}b')}}/{{Hostname}}`, "A}}B/127.0.0.1:8080"},
+		{`{{toupper("a\"}}")}}`, `A"}}`},
+		{"{{7*'7}}/{{Hostname}}", "{{7*'7}}/127.0.0.1:8080"},
+	}
+
+	for _, test := range tests {
+		result, err := evaluate(test.data, values)
+		require.Nil(t, err, "Could not evaluate %s", test.data)
+		require.Equal(t, test.expected, result, "Could not evaluate %s", test.data)
+	}
+}
+
+func TestValidateExpressions(t *testing.T) {
+	err := validateExpressions("{{BaseURL}}/?q={{config.items()}}", "{{constructor.constructor('alert(1)')()}}", "{{7*7}}")
+	require.Nil(t, err, "Could not validate template injection payloads")
+
+	err = validateExpressions(`{{md5("}}")}}`)
+	require.Nil(t, err, "Could not validate literal with closing delimiter")
+
+	err = validateExpressions("{{md5(Hostname}}")
+	require.NotNil(t, err, "Could validate invalid helper function call")
+}
+
+func TestTemplateInjectionPayloadsSent(t *testing.T) {
+	request := &BulkHTTPRequest{
+		Method:  "POST",
+		Path:    []string{"{{BaseURL}}/?q={{config.items()}}"},
+		Headers: map[string]string{"X-Payload": "{{7*7}}"},
+		Body:    "{{constructor.constructor('alert(1)')()}}",
+	}
+	require.Nil(t, request.Validate(), "Could not validate request")
+
+	compiled, err := request.MakeHTTPRequest(context.Background(), "http://127.0.0.1:8080", "", nil, request.Path[0])
+	require.Nil(t, err, "Could not make request")
+	require.Equal(t, "q={{config.items()}}", compiled.Request.URL.RawQuery, "Could not send query as written")
+	require.Equal(t, "{{7*7}}", compiled.Request.Header.Get("X-Payload"), "Could not send header as written")
+
+	body, err := compiled.Request.BodyBytes()
+	require.Nil(t, err, "Could not read body")
+	require.Equal(t, "{{constructor.constructor('alert(1)')()}}", string(body), "Could not send body as written")
+
+	raw := "GET /?q={{7*7}}&h={{Hostname}} HTTP/1.1\nHost: {{Hostname}}\n\n"
+	request = &BulkHTTPRequest{Unsafe: true, Raw: []string{raw}}
+	require.Nil(t, request.Validate(), "Could not validate raw request")
+
+	compiled, err = request.MakeHTTPRequest(context.Background(), "http://127.0.0.1:8080", "", nil, raw)
+	require.Nil(t, err, "Could not make raw request")
+	require.Equal(t, "GET /?q={{7*7}}&h=127.0.0.1:8080 HTTP/1.1\nHost: 127.0.0.1:8080\n\n", string(compiled.Unsafe.Data), "Could not send raw request as written")
+}
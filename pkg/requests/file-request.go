@@ -0,0 +1,111 @@
+package requests
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/extractors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+)
+
+// defaultFileMaxSize is the maximum size of the files to scan if none was specified
+const defaultFileMaxSize = 5 * 1024 * 1024
+
+// FileRequest contains a local file scanning request to be made from a template
+type FileRequest struct {
+	// Extensions is the list of file extensions to scan. By default, all the files are scanned.
+	Extensions []string `yaml:"extensions,omitempty"`
+	// ExtensionDenylist is the list of file extensions to never scan.
+	ExtensionDenylist []string `yaml:"denylist,omitempty"`
+	// MaxSize is the maximum size in bytes of the files to scan. Bigger files are skipped.
+	MaxSize int64 `yaml:"max-size,omitempty"`
+	// NoRecursive specifies whether directories should not be walked recursively.
+	NoRecursive bool `yaml:"no-recursive,omitempty"`
+
+	// Matchers contains the detection mechanism for the request to identify
+	// whether the request was successful
+	Matchers []*matchers.Matcher `yaml:"matchers,omitempty"`
+	// matchersCondition is internal condition for the matchers.
+	matchersCondition matchers.ConditionType
+	// MatchersCondition is the condition of the matchers
+	// whether to use AND or OR. Default is OR.
+	MatchersCondition string `yaml:"matchers-condition,omitempty"`
+	// Extractors contains the extraction mechanism for the request to identify
+	// and extract parts of the response.
+	Extractors []*extractors.Extractor `yaml:"extractors,omitempty"`
+
+	extensions map[string]struct{}
+	denylist   map[string]struct{}
+	allowAll   bool
+}
+
+// GetMatchersCondition returns the condition for the matcher
+func (r *FileRequest) GetMatchersCondition() matchers.ConditionType {
+	return r.matchersCondition
+}
+
+// SetMatchersCondition sets the condition for the matcher
+func (r *FileRequest) SetMatchersCondition(condition matchers.ConditionType) {
+	r.matchersCondition = condition
+}
+
+// GetRequestCount returns the total number of requests the YAML rule will perform
+func (r *FileRequest) GetRequestCount() int64 {
+	return 1
+}
+
+// GetMaxSize returns the maximum size of the files to scan
+func (r *FileRequest) GetMaxSize() int64 {
+	if r.MaxSize <= 0 {
+		return defaultFileMaxSize
+	}
+
+	return r.MaxSize
+}
+
+// Compile normalizes the extensions allow and deny lists of the request
+func (r *FileRequest) Compile() {
+	r.extensions = make(map[string]struct{})
+	r.denylist = make(map[string]struct{})
+	r.allowAll = len(r.Extensions) == 0
+
+	for _, extension := range r.Extensions {
+		if extension == "all" || extension == "*" {
+			r.allowAll = true
+			continue
+		}
+
+		r.extensions[normalizeExtension(extension)] = struct{}{}
+	}
+
+	for _, extension := range r.ExtensionDenylist {
+		r.denylist[normalizeExtension(extension)] = struct{}{}
+	}
+}
+
+// ShouldScan checks if a file must be scanned according to its extension
+func (r *FileRequest) ShouldScan(path string) bool {
+	extension := strings.ToLower(filepath.Ext(path))
+
+	if _, denied := r.denylist[extension]; denied {
+		return false
+	}
+
+	if r.allowAll {
+		return true
+	}
+
+	_, allowed := r.extensions[extension]
+
+	return allowed
+}
+
+// normalizeExtension returns the lowercase extension with a leading dot
+func normalizeExtension(extension string) string {
+	extension = strings.ToLower(strings.TrimSpace(extension))
+	if !strings.HasPrefix(extension, ".") {
+		extension = "." + extension
+	}
+
+	return extension
+}
@@ -0,0 +1,178 @@
+package requests
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// MultipartField is a field of a multipart/form-data body
+type MultipartField struct {
+	// Name is the name of the form field
+	Name string `yaml:"name"`
+	// Value is the content of the field
+	Value string `yaml:"value,omitempty"`
+	// File is a file to send as the content of the field instead of the value,
+	// relative to the template. Its content is sent as is.
+	File string `yaml:"file,omitempty"`
+	// Filename is the filename of the field, making it a file upload
+	Filename string `yaml:"filename,omitempty"`
+	// ContentType is the content type of the field. Default is
+	// application/octet-stream for file uploads.
+	ContentType string `yaml:"content-type,omitempty"`
+	// data contains the content of the file, if any
+	data []byte
+}
+
+// quoteEscaper escapes the values of the content disposition header
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// LoadBody validates the body options of the request and loads the files they
+// refer to. Relative paths are resolved from the directory of the template.
+func (r *BulkHTTPRequest) LoadBody(templatePath string) error {
+	options := 0
+
+	for _, set := range []bool{r.Body != "", r.BodyFile != "", len(r.Multipart) > 0, len(r.Form) > 0} {
+		if set {
+			options++
+		}
+	}
+
+	if options > 1 {
+		return errors.New("only one of body, body-file, multipart and form can be used")
+	}
+
+	if r.BodyFile != "" {
+		data, err := readTemplateFile(templatePath, r.BodyFile)
+		if err != nil {
+			return err
+		}
+
+		r.bodyFile = data
+	}
+
+	for _, field := range r.Multipart {
+		if field.Name == "" {
+			return errors.New("multipart field without name")
+		}
+
+		if field.File == "" {
+			continue
+		}
+
+		data, err := readTemplateFile(templatePath, field.File)
+		if err != nil {
+			return err
+		}
+
+		field.data = data
+	}
+
+	return nil
+}
+
+// readTemplateFile reads a file, relative paths being relative to the directory of the template
+func readTemplateFile(templatePath, file string) ([]byte, error) {
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(filepath.Dir(templatePath), file)
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read body file: %s", err)
+	}
+
+	return data, nil
+}
+
+// makeBody creates the body of the request with its content type, if any.
+// The expressions are evaluated in the values but not in the files.
+func (r *BulkHTTPRequest) makeBody(values map[string]interface{}) (body []byte, contentType string, err error) {
+	switch {
+	case r.Body != "":
+		data, err := evaluate(r.Body, values)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return []byte(data), "", nil
+	case r.bodyFile != nil:
+		return r.bodyFile, "", nil
+	case len(r.Form) > 0:
+		form := make(url.Values)
+
+		for key, value := range r.Form {
+			fields, err := evaluateAll(values, key, value)
+			if err != nil {
+				return nil, "", err
+			}
+
+			form.Set(fields[0], fields[1])
+		}
+
+		return []byte(form.Encode()), "application/x-www-form-urlencoded", nil
+	case len(r.Multipart) > 0:
+		buffer := &bytes.Buffer{}
+		writer := multipart.NewWriter(buffer)
+
+		for _, field := range r.Multipart {
+			if err := writeMultipartField(writer, field, values); err != nil {
+				return nil, "", err
+			}
+		}
+
+		if err := writer.Close(); err != nil {
+			return nil, "", err
+		}
+
+		return buffer.Bytes(), writer.FormDataContentType(), nil
+	}
+
+	return nil, "", nil
+}
+
+// writeMultipartField writes a field of a multipart body
+func writeMultipartField(writer *multipart.Writer, field *MultipartField, values map[string]interface{}) error {
+	fields, err := evaluateAll(values, field.Name, field.Filename, field.ContentType, field.Value)
+	if err != nil {
+		return err
+	}
+
+	name, filename, contentType, value := fields[0], fields[1], fields[2], fields[3]
+
+	disposition := fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(name))
+	if filename != "" {
+		disposition += fmt.Sprintf(`; filename="%s"`, quoteEscaper.Replace(filename))
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", disposition)
+
+	if contentType == "" && filename != "" {
+		contentType = "application/octet-stream"
+	}
+
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	data := field.data
+	if data == nil {
+		data = []byte(value)
+	}
+
+	_, err = part.Write(data)
+
+	return err
+}
@@ -0,0 +1,33 @@
+package requests
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBodyRelativeToTemplate(t *testing.T) {
+	directory, err := ioutil.TempDir("", "nuclei-body-")
+	require.Nil(t, err, "Could not create temporary directory")
+
+	defer os.RemoveAll(directory)
+
+	err = ioutil.WriteFile(filepath.Join(directory, "body.txt"), []byte("template body"), 0644)
+	require.Nil(t, err, "Could not write body file")
+
+	templatePath := filepath.Join(directory, "template.yaml")
+
+	request := &BulkHTTPRequest{BodyFile: "body.txt"}
+	require.Nil(t, request.LoadBody(templatePath), "Could not load body file relative to template")
+	require.Equal(t, "template body", string(request.bodyFile), "Could not read body file")
+
+	// Files of the working directory are not read for relative paths
+	request = &BulkHTTPRequest{BodyFile: "http-body.go"}
+	require.NotNil(t, request.LoadBody(templatePath), "Could load body file relative to working directory")
+
+	request = &BulkHTTPRequest{BodyFile: filepath.Join(directory, "body.txt")}
+	require.Nil(t, request.LoadBody(filepath.Join(os.TempDir(), "other", "template.yaml")), "Could not load absolute body file")
+}
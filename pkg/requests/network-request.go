@@ -0,0 +1,200 @@
+package requests
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/extractors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+)
+
+// defaultNetworkReadSize is the number of bytes read from the connection
+// after all the inputs have been sent if no read-size has been specified.
+const defaultNetworkReadSize = 1024
+
+// NetworkRequest contains a raw TCP/UDP request to be made from a template
+type NetworkRequest struct {
+	// Address contains the host addresses to connect to.
+	// By default, the request is sent to {{Hostname}}.
+	Address []string `yaml:"host,omitempty"`
+	// Port is the port to connect to on the host if the address doesn't specify one.
+	Port string `yaml:"port,omitempty"`
+	// Protocol is the transport protocol to use, tcp or udp. Default is tcp.
+	Protocol string `yaml:"protocol,omitempty"`
+	// Inputs contains the data to send to the server in order
+	Inputs []*NetworkInput `yaml:"inputs,omitempty"`
+	// ReadSize is the size of the response to read once all the inputs have been sent.
+	ReadSize int `yaml:"read-size,omitempty"`
+
+	// Matchers contains the detection mechanism for the request to identify
+	// whether the request was successful
+	Matchers []*matchers.Matcher `yaml:"matchers,omitempty"`
+	// matchersCondition is internal condition for the matchers.
+	matchersCondition matchers.ConditionType
+	// MatchersCondition is the condition of the matchers
+	// whether to use AND or OR. Default is OR.
+	MatchersCondition string `yaml:"matchers-condition,omitempty"`
+	// Extractors contains the extraction mechanism for the request to identify
+	// and extract parts of the response.
+	Extractors []*extractors.Extractor `yaml:"extractors,omitempty"`
+}
+
+// NetworkInput is a single piece of data to send to the server
+type NetworkInput struct {
+	// Data is the data to send to the server
+	Data string `yaml:"data"`
+	// Type is the type of the data, either text or hex. Default is text.
+	Type string `yaml:"type,omitempty"`
+	// Read is the number of bytes to read from the server after sending the data
+	Read int `yaml:"read,omitempty"`
+}
+
+// CompiledNetworkInput is a network input with all the variables replaced
+// and the data decoded, ready to be written to the connection.
+type CompiledNetworkInput struct {
+	Data []byte
+	Read int
+}
+
+// CompiledNetworkRequest is a network request for a single address
+type CompiledNetworkRequest struct {
+	Address  string
+	Protocol string
+	Inputs   []*CompiledNetworkInput
+	ReadSize int
+}
+
+// GetMatchersCondition returns the condition for the matcher
+func (r *NetworkRequest) GetMatchersCondition() matchers.ConditionType {
+	return r.matchersCondition
+}
+
+// SetMatchersCondition sets the condition for the matcher
+func (r *NetworkRequest) SetMatchersCondition(condition matchers.ConditionType) {
+	r.matchersCondition = condition
+}
+
+// GetRequestCount returns the total number of requests the YAML rule will perform
+func (r *NetworkRequest) GetRequestCount() int64 {
+	if len(r.Address) == 0 {
+		return 1
+	}
+
+	return int64(len(r.Address))
+}
+
+// Validate checks the network request for any invalid value
+func (r *NetworkRequest) Validate() error {
+	switch strings.ToLower(r.Protocol) {
+	case "", "tcp", "udp":
+	default:
+		return fmt.Errorf("unknown network protocol specified: %s", r.Protocol)
+	}
+
+	fields := append([]string{}, r.Address...)
+
+	for _, input := range r.Inputs {
+		switch strings.ToLower(input.Type) {
+		case "", "text":
+		case "hex":
+			if _, err := hex.DecodeString(input.Data); err != nil && !strings.Contains(input.Data, "{{") {
+				return fmt.Errorf("could not decode hex input '%s': %s", input.Data, err)
+			}
+		default:
+			return fmt.Errorf("unknown network input type specified: %s", input.Type)
+		}
+
+		fields = append(fields, input.Data)
+	}
+
+	return validateExpressions(fields...)
+}
+
+// MakeNetworkRequests creates the network requests for a host, one for each address.
+// The values are variables extracted by previous requests of the template, if any.
+func (r *NetworkRequest) MakeNetworkRequests(host string, values map[string]interface{}) ([]*CompiledNetworkRequest, error) {
+	values = generators.MergeMaps(values, map[string]interface{}{"Hostname": host})
+
+	addresses := r.Address
+	if len(addresses) == 0 {
+		addresses = []string{"{{Hostname}}"}
+	}
+
+	protocol := strings.ToLower(r.Protocol)
+	if protocol == "" {
+		protocol = "tcp"
+	}
+
+	readSize := r.ReadSize
+	if readSize <= 0 {
+		readSize = defaultNetworkReadSize
+	}
+
+	inputs := make([]*CompiledNetworkInput, 0, len(r.Inputs))
+
+	for _, input := range r.Inputs {
+		data, err := evaluate(input.Data, values)
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.EqualFold(input.Type, "hex") {
+			decoded, err := hex.DecodeString(data)
+			if err != nil {
+				return nil, fmt.Errorf("could not decode hex input: %s", err)
+			}
+
+			inputs = append(inputs, &CompiledNetworkInput{Data: decoded, Read: input.Read})
+
+			continue
+		}
+
+		inputs = append(inputs, &CompiledNetworkInput{Data: []byte(data), Read: input.Read})
+	}
+
+	compiled := make([]*CompiledNetworkRequest, 0, len(addresses))
+
+	for _, address := range addresses {
+		address, err := evaluate(address, values)
+		if err != nil {
+			return nil, err
+		}
+
+		address, err = r.resolveAddress(address)
+		if err != nil {
+			return nil, err
+		}
+
+		compiled = append(compiled, &CompiledNetworkRequest{
+			Address:  address,
+			Protocol: protocol,
+			Inputs:   inputs,
+			ReadSize: readSize,
+		})
+	}
+
+	return compiled, nil
+}
+
+// resolveAddress returns the address with the port to connect to.
+// If a port is specified in the request, it overrides the one in the address.
+func (r *NetworkRequest) resolveAddress(address string) (string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+		port = ""
+	}
+
+	if r.Port != "" {
+		port = r.Port
+	}
+
+	if port == "" {
+		return "", fmt.Errorf("no port specified for address %s", address)
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
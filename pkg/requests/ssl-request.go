@@ -0,0 +1,179 @@
+package requests
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/extractors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+)
+
+// defaultSSLPort is the port used for ssl requests if none was specified
+const defaultSSLPort = "443"
+
+// SSLRequest contains a TLS/SSL inspection request to be made from a template
+type SSLRequest struct {
+	// Address contains the host address to connect to.
+	// By default, the request is sent to {{Hostname}}.
+	Address string `yaml:"host,omitempty"`
+	// Port is the port to connect to on the host if the address doesn't specify one.
+	Port string `yaml:"port,omitempty"`
+	// ServerName is the optional SNI server name to send in the handshake.
+	// By default, the host of the address is used.
+	ServerName string `yaml:"server-name,omitempty"`
+	// MinVersion is the minimum TLS version to negotiate (tls10, tls11, tls12, tls13)
+	MinVersion string `yaml:"min-version,omitempty"`
+	// MaxVersion is the maximum TLS version to negotiate (tls10, tls11, tls12, tls13)
+	MaxVersion string `yaml:"max-version,omitempty"`
+	// CipherSuites is the list of cipher suites to offer in the handshake.
+	// By default, the go defaults are used.
+	CipherSuites []string `yaml:"cipher-suites,omitempty"`
+
+	// Matchers contains the detection mechanism for the request to identify
+	// whether the request was successful
+	Matchers []*matchers.Matcher `yaml:"matchers,omitempty"`
+	// matchersCondition is internal condition for the matchers.
+	matchersCondition matchers.ConditionType
+	// MatchersCondition is the condition of the matchers
+	// whether to use AND or OR. Default is OR.
+	MatchersCondition string `yaml:"matchers-condition,omitempty"`
+	// Extractors contains the extraction mechanism for the request to identify
+	// and extract parts of the response.
+	Extractors []*extractors.Extractor `yaml:"extractors,omitempty"`
+
+	minVersion   uint16
+	maxVersion   uint16
+	cipherSuites []uint16
+}
+
+// CompiledSSLRequest is a ssl request for a single address
+type CompiledSSLRequest struct {
+	Address string
+	Config  *tls.Config
+}
+
+// TLSVersions is a table for conversion of tls versions from string.
+var TLSVersions = map[string]uint16{
+	"tls10": tls.VersionTLS10,
+	"tls11": tls.VersionTLS11,
+	"tls12": tls.VersionTLS12,
+	"tls13": tls.VersionTLS13,
+}
+
+// TLSVersionToString returns the name of a tls version
+func TLSVersionToString(version uint16) string {
+	for name, value := range TLSVersions {
+		if value == version {
+			return name
+		}
+	}
+
+	return fmt.Sprintf("unknown(0x%04x)", version)
+}
+
+// GetMatchersCondition returns the condition for the matcher
+func (r *SSLRequest) GetMatchersCondition() matchers.ConditionType {
+	return r.matchersCondition
+}
+
+// SetMatchersCondition sets the condition for the matcher
+func (r *SSLRequest) SetMatchersCondition(condition matchers.ConditionType) {
+	r.matchersCondition = condition
+}
+
+// GetRequestCount returns the total number of requests the YAML rule will perform
+func (r *SSLRequest) GetRequestCount() int64 {
+	return 1
+}
+
+// Compile validates and converts the tls versions and cipher suites of the request
+func (r *SSLRequest) Compile() error {
+	var ok bool
+
+	if r.MinVersion != "" {
+		if r.minVersion, ok = TLSVersions[strings.ToLower(r.MinVersion)]; !ok {
+			return fmt.Errorf("unknown tls version specified: %s", r.MinVersion)
+		}
+	}
+
+	if r.MaxVersion != "" {
+		if r.maxVersion, ok = TLSVersions[strings.ToLower(r.MaxVersion)]; !ok {
+			return fmt.Errorf("unknown tls version specified: %s", r.MaxVersion)
+		}
+	}
+
+	// Newer go versions default to a higher minimum version than the maximum which may be asked
+	if r.minVersion == 0 && r.maxVersion != 0 {
+		r.minVersion = tls.VersionTLS10
+	}
+
+	if r.minVersion != 0 && r.maxVersion != 0 && r.minVersion > r.maxVersion {
+		return fmt.Errorf("min-version %s is greater than max-version %s", r.MinVersion, r.MaxVersion)
+	}
+
+	suites := make(map[string]uint16)
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		suites[suite.Name] = suite.ID
+	}
+
+	r.cipherSuites = nil
+
+	for _, name := range r.CipherSuites {
+		id, ok := suites[name]
+		if !ok {
+			return fmt.Errorf("unknown cipher suite specified: %s", name)
+		}
+
+		r.cipherSuites = append(r.cipherSuites, id)
+	}
+
+	return validateExpressions(r.Address, r.ServerName)
+}
+
+// MakeSSLRequest creates the tls configuration and the address to connect to for a host.
+// The values are variables extracted by previous requests of the template, if any.
+func (r *SSLRequest) MakeSSLRequest(host string, values map[string]interface{}) (*CompiledSSLRequest, error) {
+	values = generators.MergeMaps(values, map[string]interface{}{"Hostname": host})
+
+	address := r.Address
+	if address == "" {
+		address = "{{Hostname}}"
+	}
+
+	address, err := evaluate(address, values)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, port, err := net.SplitHostPort(address)
+	if err != nil {
+		hostname = address
+
+		port = r.Port
+		if port == "" {
+			port = defaultSSLPort
+		}
+	}
+
+	serverName := hostname
+	if r.ServerName != "" {
+		serverName, err = evaluate(r.ServerName, values)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	config := &tls.Config{
+		// Certificates are verified manually so that invalid ones can be inspected too
+		InsecureSkipVerify: true,
+		ServerName:         serverName,
+		MinVersion:         r.minVersion,
+		MaxVersion:         r.maxVersion,
+		CipherSuites:       r.cipherSuites,
+	}
+
+	return &CompiledSSLRequest{Address: net.JoinHostPort(hostname, port), Config: config}, nil
+}
@@ -0,0 +1,43 @@
+package requests
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSLRequestVersions(t *testing.T) {
+	request := &SSLRequest{MaxVersion: "tls11"}
+	require.Nil(t, request.Compile(), "Could not compile request with max version")
+
+	compiled, err := request.MakeSSLRequest("example.com", nil)
+	require.Nil(t, err, "Could not make request")
+	require.Equal(t, uint16(tls.VersionTLS10), compiled.Config.MinVersion, "Could not set min version")
+	require.Equal(t, uint16(tls.VersionTLS11), compiled.Config.MaxVersion, "Could not set max version")
+
+	request = &SSLRequest{MinVersion: "tls13", MaxVersion: "tls12"}
+	require.NotNil(t, request.Compile(), "Could compile request with min version above max version")
+}
+
+func TestSSLRequestAddress(t *testing.T) {
+	tests := []struct {
+		address  string
+		port     string
+		expected string
+	}{
+		{"", "", "example.com:443"},
+		{"", "8443", "example.com:8443"},
+		{"{{Hostname}}:9443", "8443", "example.com:9443"},
+		{"other.com", "", "other.com:443"},
+	}
+
+	for _, test := range tests {
+		request := &SSLRequest{Address: test.address, Port: test.port}
+		require.Nil(t, request.Compile(), "Could not compile request")
+
+		compiled, err := request.MakeSSLRequest("example.com", nil)
+		require.Nil(t, err, "Could not make request")
+		require.Equal(t, test.expected, compiled.Address, "Could not make address for %s", test.address)
+	}
+}
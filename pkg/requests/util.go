@@ -21,6 +21,11 @@ func newReplacer(values map[string]interface{}) *strings.Replacer {
 
 // HandleDecompression if the user specified a custom encoding (as golang transport doesn't do this automatically)
 func HandleDecompression(r *retryablehttp.Request, bodyOrig []byte) (bodyDec []byte, err error) {
+	// unsafe requests have no parsed request and their responses are left untouched
+	if r == nil {
+		return bodyOrig, nil
+	}
+
 	encodingHeader := strings.ToLower(r.Header.Get("Accept-Encoding"))
 	if encodingHeader == "gzip" {
 		gzipreader, err := gzip.NewReader(bytes.NewReader(bodyOrig))
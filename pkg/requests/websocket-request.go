@@ -0,0 +1,186 @@
+package requests
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	"github.com/projectdiscovery/nuclei/v2/pkg/extractors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/matchers"
+)
+
+// WebSocketRequest contains a websocket request to be made from a template
+type WebSocketRequest struct {
+	// Address is the websocket URL to connect to. http and https schemes
+	// are converted to ws and wss. By default, {{BaseURL}} is used.
+	Address string `yaml:"address,omitempty"`
+	// Origin is the origin header to send in the handshake, used
+	// to check for cross-site websocket hijacking.
+	Origin string `yaml:"origin,omitempty"`
+	// Headers contains headers to send with the handshake request
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// Inputs contains the messages to send to the server in order
+	Inputs []*WebSocketInput `yaml:"inputs,omitempty"`
+
+	// Matchers contains the detection mechanism for the request to identify
+	// whether the request was successful
+	Matchers []*matchers.Matcher `yaml:"matchers,omitempty"`
+	// matchersCondition is internal condition for the matchers.
+	matchersCondition matchers.ConditionType
+	// MatchersCondition is the condition of the matchers
+	// whether to use AND or OR. Default is OR.
+	MatchersCondition string `yaml:"matchers-condition,omitempty"`
+	// Extractors contains the extraction mechanism for the request to identify
+	// and extract parts of the response.
+	Extractors []*extractors.Extractor `yaml:"extractors,omitempty"`
+}
+
+// WebSocketInput is a single message to send to the server
+type WebSocketInput struct {
+	// Data is the message to send to the server
+	Data string `yaml:"data"`
+	// Type is the type of the data, either text or hex. Hex data is sent
+	// as a binary message. Default is text.
+	Type string `yaml:"type,omitempty"`
+	// Read is the number of messages to read from the server after sending the data
+	Read int `yaml:"read,omitempty"`
+}
+
+// CompiledWebSocketMessage is a websocket message ready to be sent to the server
+type CompiledWebSocketMessage struct {
+	Type int
+	Data []byte
+	Read int
+}
+
+// CompiledWebSocketRequest is a websocket request for a single target
+type CompiledWebSocketRequest struct {
+	URL      string
+	Headers  http.Header
+	Messages []*CompiledWebSocketMessage
+}
+
+// GetMatchersCondition returns the condition for the matcher
+func (r *WebSocketRequest) GetMatchersCondition() matchers.ConditionType {
+	return r.matchersCondition
+}
+
+// SetMatchersCondition sets the condition for the matcher
+func (r *WebSocketRequest) SetMatchersCondition(condition matchers.ConditionType) {
+	r.matchersCondition = condition
+}
+
+// GetRequestCount returns the total number of requests the YAML rule will perform
+func (r *WebSocketRequest) GetRequestCount() int64 {
+	return 1
+}
+
+// Validate checks the websocket request for any invalid value
+func (r *WebSocketRequest) Validate() error {
+	fields := []string{r.Address, r.Origin}
+
+	for _, value := range r.Headers {
+		fields = append(fields, value)
+	}
+
+	for _, input := range r.Inputs {
+		switch strings.ToLower(input.Type) {
+		case "", "text", "hex":
+		default:
+			return fmt.Errorf("unknown websocket input type specified: %s", input.Type)
+		}
+
+		fields = append(fields, input.Data)
+	}
+
+	return validateExpressions(fields...)
+}
+
+// MakeWebSocketRequest creates the handshake and the messages to send for a base URL.
+// The values are variables extracted by previous requests of the template, if any.
+func (r *WebSocketRequest) MakeWebSocketRequest(baseURL string, values map[string]interface{}) (*CompiledWebSocketRequest, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	values = generators.MergeMaps(values, map[string]interface{}{
+		"BaseURL":  baseURL,
+		"Hostname": parsed.Host,
+	})
+
+	address := r.Address
+	if address == "" {
+		address = "{{BaseURL}}"
+	}
+
+	address, err = evaluate(address, values)
+	if err != nil {
+		return nil, err
+	}
+
+	wsURL, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(wsURL.Scheme) {
+	case "http", "ws":
+		wsURL.Scheme = "ws"
+	case "https", "wss":
+		wsURL.Scheme = "wss"
+	default:
+		return nil, fmt.Errorf("unsupported websocket scheme: %s", wsURL.Scheme)
+	}
+
+	headers := make(http.Header)
+	for header, value := range r.Headers {
+		value, err := evaluate(value, values)
+		if err != nil {
+			return nil, err
+		}
+
+		headers[header] = []string{value}
+	}
+
+	if r.Origin != "" {
+		origin, err := evaluate(r.Origin, values)
+		if err != nil {
+			return nil, err
+		}
+
+		headers.Set("Origin", origin)
+	}
+
+	if headers.Get("User-Agent") == "" {
+		headers.Set("User-Agent", "Nuclei - Open-source project (github.com/projectdiscovery/nuclei)")
+	}
+
+	messages := make([]*CompiledWebSocketMessage, 0, len(r.Inputs))
+
+	for _, input := range r.Inputs {
+		data, err := evaluate(input.Data, values)
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.EqualFold(input.Type, "hex") {
+			decoded, err := hex.DecodeString(data)
+			if err != nil {
+				return nil, fmt.Errorf("could not decode hex input: %s", err)
+			}
+
+			messages = append(messages, &CompiledWebSocketMessage{Type: websocket.BinaryMessage, Data: decoded, Read: input.Read})
+
+			continue
+		}
+
+		messages = append(messages, &CompiledWebSocketMessage{Type: websocket.TextMessage, Data: []byte(data), Read: input.Read})
+	}
+
+	return &CompiledWebSocketRequest{URL: wsURL.String(), Headers: headers, Messages: messages}, nil
+}
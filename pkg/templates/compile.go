@@ -2,7 +2,7 @@ package templates
 
 import (
 	"fmt"
-	"os"
+	"io/ioutil"
 	"path"
 	"strings"
 
@@ -13,26 +13,36 @@ import (
 
 // Parse parses a yaml request template file
 func Parse(file string) (*Template, error) {
-	template := &Template{}
-
-	f, err := os.Open(file)
+	data, err := ioutil.ReadFile(file)
 	if err != nil {
 		return nil, err
 	}
 
-	err = yaml.NewDecoder(f).Decode(template)
+	return ParseBytes(data, file)
+}
+
+// ParseBytes parses a yaml request template from memory. The file is
+// the path used to resolve the payload files relative to the template.
+func ParseBytes(data []byte, file string) (*Template, error) {
+	template := &Template{}
+
+	err := yaml.Unmarshal(data, template)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
 
 	template.path = file
 
 	// If no requests, and it is also not a workflow, return error.
-	if len(template.BulkRequestsHTTP)+len(template.RequestsDNS) <= 0 {
+	if len(template.BulkRequestsHTTP)+len(template.RequestsDNS)+len(template.RequestsNetwork)+len(template.RequestsSSL)+len(template.RequestsFile)+len(template.RequestsWebSocket) <= 0 {
 		return nil, fmt.Errorf("no requests defined for %s", template.ID)
 	}
 
+	err = template.orderRequests(data)
+	if err != nil {
+		return nil, err
+	}
+
 	// Compile the matchers and the extractors for http requests
 	for _, request := range template.BulkRequestsHTTP {
 		// Get the condition between the matchers
@@ -51,6 +61,16 @@ func Parse(file string) (*Template, error) {
 			request.SetAttackType(attack)
 		}
 
+		err = request.Validate()
+		if err != nil {
+			return nil, err
+		}
+
+		err = request.LoadBody(template.path)
+		if err != nil {
+			return nil, err
+		}
+
 		// Validate the payloads if any
 		for name, payload := range request.Payloads {
 			switch pt := payload.(type) {
@@ -92,6 +112,11 @@ func Parse(file string) (*Template, error) {
 			if matchErr != nil {
 				return nil, matchErr
 			}
+
+			matchErr = matcher.ValidateProtocol("http")
+			if matchErr != nil {
+				return nil, matchErr
+			}
 		}
 
 		for _, extractor := range request.Extractors {
@@ -114,11 +139,158 @@ func Parse(file string) (*Template, error) {
 			request.SetMatchersCondition(condition)
 		}
 
+		err = request.Validate()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, matcher := range request.Matchers {
+			err = matcher.CompileMatchers()
+			if err != nil {
+				return nil, err
+			}
+
+			err = matcher.ValidateProtocol("dns")
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, extractor := range request.Extractors {
+			err := extractor.CompileExtractors()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Compile the matchers and the extractors for network requests
+	for _, request := range template.RequestsNetwork {
+		// Get the condition between the matchers
+		condition, ok := matchers.ConditionTypes[request.MatchersCondition]
+		if !ok {
+			request.SetMatchersCondition(matchers.ORCondition)
+		} else {
+			request.SetMatchersCondition(condition)
+		}
+
+		err = request.Validate()
+		if err != nil {
+			return nil, err
+		}
+
 		for _, matcher := range request.Matchers {
 			err = matcher.CompileMatchers()
 			if err != nil {
 				return nil, err
 			}
+
+			err = matcher.ValidateProtocol("network")
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, extractor := range request.Extractors {
+			err := extractor.CompileExtractors()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Compile the matchers and the extractors for ssl requests
+	for _, request := range template.RequestsSSL {
+		// Get the condition between the matchers
+		condition, ok := matchers.ConditionTypes[request.MatchersCondition]
+		if !ok {
+			request.SetMatchersCondition(matchers.ORCondition)
+		} else {
+			request.SetMatchersCondition(condition)
+		}
+
+		err = request.Compile()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, matcher := range request.Matchers {
+			err = matcher.CompileMatchers()
+			if err != nil {
+				return nil, err
+			}
+
+			err = matcher.ValidateProtocol("ssl")
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, extractor := range request.Extractors {
+			err := extractor.CompileExtractors()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Compile the matchers and the extractors for file requests
+	for _, request := range template.RequestsFile {
+		// Get the condition between the matchers
+		condition, ok := matchers.ConditionTypes[request.MatchersCondition]
+		if !ok {
+			request.SetMatchersCondition(matchers.ORCondition)
+		} else {
+			request.SetMatchersCondition(condition)
+		}
+
+		request.Compile()
+
+		for _, matcher := range request.Matchers {
+			err = matcher.CompileMatchers()
+			if err != nil {
+				return nil, err
+			}
+
+			err = matcher.ValidateProtocol("file")
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, extractor := range request.Extractors {
+			err := extractor.CompileExtractors()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Compile the matchers and the extractors for websocket requests
+	for _, request := range template.RequestsWebSocket {
+		// Get the condition between the matchers
+		condition, ok := matchers.ConditionTypes[request.MatchersCondition]
+		if !ok {
+			request.SetMatchersCondition(matchers.ORCondition)
+		} else {
+			request.SetMatchersCondition(condition)
+		}
+
+		err = request.Validate()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, matcher := range request.Matchers {
+			err = matcher.CompileMatchers()
+			if err != nil {
+				return nil, err
+			}
+
+			err = matcher.ValidateProtocol("websocket")
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		for _, extractor := range request.Extractors {
@@ -2,6 +2,7 @@ package templates
 
 import (
 	"github.com/projectdiscovery/nuclei/v2/pkg/requests"
+	"gopkg.in/yaml.v2"
 )
 
 // Template is a request template parsed from a yaml file
@@ -10,11 +11,25 @@ type Template struct {
 	ID string `yaml:"id"`
 	// Info contains information about the template
 	Info Info `yaml:"info"`
+	// Ordered specifies whether the requests of the template are run one after
+	// the other for each target, in the order they are declared. Values extracted
+	// by a request are then available as variables in the following ones.
+	Ordered bool `yaml:"ordered,omitempty"`
 	// BulkRequestsHTTP contains the http request to make in the template
 	BulkRequestsHTTP []*requests.BulkHTTPRequest `yaml:"requests,omitempty"`
 	// RequestsDNS contains the dns request to make in the template
 	RequestsDNS []*requests.DNSRequest `yaml:"dns,omitempty"`
-	path        string
+	// RequestsNetwork contains the raw network request to make in the template
+	RequestsNetwork []*requests.NetworkRequest `yaml:"network,omitempty"`
+	// RequestsSSL contains the tls/ssl inspection request to make in the template
+	RequestsSSL []*requests.SSLRequest `yaml:"ssl,omitempty"`
+	// RequestsFile contains the local file scanning request to make in the template
+	RequestsFile []*requests.FileRequest `yaml:"file,omitempty"`
+	// RequestsWebSocket contains the websocket request to make in the template
+	RequestsWebSocket []*requests.WebSocketRequest `yaml:"websocket,omitempty"`
+	path              string
+	// requests contains all the requests in the order they are declared
+	requests []interface{}
 }
 
 // GetPath of the workflow
@@ -22,16 +37,22 @@ func (t *Template) GetPath() string {
 	return t.path
 }
 
+// GetRequests returns all the requests of the template in the
+// order they are declared in the template file.
+func (t *Template) GetRequests() []interface{} {
+	return t.requests
+}
+
 // Info contains information about the request template
 type Info struct {
 	// Name is the name of the template
-	Name string `yaml:"name"`
+	Name string `yaml:"name" json:"name"`
 	// Author is the name of the author of the template
-	Author string `yaml:"author"`
+	Author string `yaml:"author" json:"author"`
 	// Severity optionally describes the severity of the template
-	Severity string `yaml:"severity,omitempty"`
+	Severity string `yaml:"severity,omitempty" json:"severity"`
 	// Description optionally describes the template.
-	Description string `yaml:"description,omitempty"`
+	Description string `yaml:"description,omitempty" json:"description"`
 }
 
 func (t *Template) GetHTTPRequestCount() int64 {
@@ -51,3 +72,83 @@ func (t *Template) GetDNSRequestCount() int64 {
 
 	return count
 }
+
+func (t *Template) GetNetworkRequestCount() int64 {
+	var count int64 = 0
+	for _, request := range t.RequestsNetwork {
+		count += request.GetRequestCount()
+	}
+
+	return count
+}
+
+func (t *Template) GetSSLRequestCount() int64 {
+	var count int64 = 0
+	for _, request := range t.RequestsSSL {
+		count += request.GetRequestCount()
+	}
+
+	return count
+}
+
+func (t *Template) GetFileRequestCount() int64 {
+	var count int64 = 0
+	for _, request := range t.RequestsFile {
+		count += request.GetRequestCount()
+	}
+
+	return count
+}
+
+func (t *Template) GetWebSocketRequestCount() int64 {
+	var count int64 = 0
+	for _, request := range t.RequestsWebSocket {
+		count += request.GetRequestCount()
+	}
+
+	return count
+}
+
+// orderRequests sorts the requests of the template in the order their
+// protocol sections are declared in the template file.
+func (t *Template) orderRequests(data []byte) error {
+	var sections yaml.MapSlice
+
+	err := yaml.Unmarshal(data, &sections)
+	if err != nil {
+		return err
+	}
+
+	for _, section := range sections {
+		key, _ := section.Key.(string)
+
+		switch key {
+		case "requests":
+			for _, request := range t.BulkRequestsHTTP {
+				t.requests = append(t.requests, request)
+			}
+		case "dns":
+			for _, request := range t.RequestsDNS {
+				t.requests = append(t.requests, request)
+			}
+		case "network":
+			for _, request := range t.RequestsNetwork {
+				t.requests = append(t.requests, request)
+			}
+		case "ssl":
+			for _, request := range t.RequestsSSL {
+				t.requests = append(t.requests, request)
+			}
+		case "file":
+			for _, request := range t.RequestsFile {
+				t.requests = append(t.requests, request)
+			}
+		case "websocket":
+			for _, request := range t.RequestsWebSocket {
+				t.requests = append(t.requests, request)
+			}
+		}
+	}
+
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	"sync"
 
 	tengo "github.com/d5/tengo/v2"
-	"github.com/logrusorgru/aurora"
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/nuclei/v2/internal/progress"
 	"github.com/projectdiscovery/nuclei/v2/pkg/atomicboolean"
@@ -75,10 +74,6 @@ func (n *NucleiVar) Call(args ...tengo.Object) (ret tengo.Object, err error) {
 
 				template.HTTPOptions.BulkHTTPRequest = request
 
-				if template.HTTPOptions.Colorizer == nil {
-					template.HTTPOptions.Colorizer = aurora.NewAurora(true)
-				}
-
 				httpExecuter, err := executer.NewHTTPExecuter(template.HTTPOptions)
 
 				if err != nil {
@@ -88,7 +83,7 @@ func (n *NucleiVar) Call(args ...tengo.Object) (ret tengo.Object, err error) {
 					continue
 				}
 
-				result := httpExecuter.ExecuteHTTP(ctx, p, n.URL)
+				result := httpExecuter.ExecuteHTTP(ctx, p, n.URL, nil)
 
 				if result.Error != nil {
 					gologger.Warningf("Could not send request for template '%s': %s\n", template.HTTPOptions.Template.ID, result.Error)
@@ -108,7 +103,7 @@ func (n *NucleiVar) Call(args ...tengo.Object) (ret tengo.Object, err error) {
 			for _, request := range template.DNSOptions.Template.RequestsDNS {
 				template.DNSOptions.DNSRequest = request
 				dnsExecuter := executer.NewDNSExecuter(template.DNSOptions)
-				result := dnsExecuter.ExecuteDNS(p, n.URL)
+				result := dnsExecuter.ExecuteDNS(p, n.URL, nil)
 
 				if result.Error != nil {
 					gologger.Warningf("Could not compile request for template '%s': %s\n", template.HTTPOptions.Template.ID, result.Error)